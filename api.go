@@ -0,0 +1,77 @@
+package vivawallet
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	errMissingOAuth     = errors.New("operation requires an oauth client")
+	errMissingBasicAuth = errors.New("operation requires a basic auth client")
+)
+
+// API groups the Viva endpoints by resource. Viva uses OAuth for some endpoints and
+// basic authentication for others, hence a service may use either of the two clients
+// depending on the operation.
+type API struct {
+	Orders         *OrdersService
+	Transactions   *TransactionsService
+	Wallets        *WalletsService
+	Sources        *SourcesService
+	Webhooks       *WebhooksService
+	Reports        *ReportsService
+	NativeCheckout *NativeCheckoutService
+}
+
+type service struct {
+	oauth *OAuthClient
+	basic *BasicAuthClient
+}
+
+// NewAPI creates the resource services on top of the given clients. Either client may
+// be nil, in which case the operations requiring it return an error.
+func NewAPI(oauth *OAuthClient, basic *BasicAuthClient) *API {
+	s := service{oauth: oauth, basic: basic}
+	return &API{
+		Orders:         (*OrdersService)(&s),
+		Transactions:   (*TransactionsService)(&s),
+		Wallets:        (*WalletsService)(&s),
+		Sources:        (*SourcesService)(&s),
+		Webhooks:       (*WebhooksService)(&s),
+		Reports:        (*ReportsService)(&s),
+		NativeCheckout: (*NativeCheckoutService)(&s),
+	}
+}
+
+// oauthClient returns the oauth client, authenticated with a token granted the scope
+// of the operation.
+func (s service) oauthClient(ctx context.Context, scope string) (*OAuthClient, error) {
+	if s.oauth == nil {
+		return nil, errMissingOAuth
+	}
+	if err := s.oauth.ensureAuth(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.oauth.requireScope(scope); err != nil {
+		return nil, err
+	}
+	return s.oauth, nil
+}
+
+func (s service) basicClient() (*BasicAuthClient, error) {
+	if s.basic == nil {
+		return nil, errMissingBasicAuth
+	}
+	return s.basic, nil
+}
+
+// metrics returns the metrics of the clients, preferring those of the basic auth one.
+func (s service) metrics() Metrics {
+	if s.basic != nil && s.basic.Metrics != nil {
+		return s.basic.Metrics
+	}
+	if s.oauth != nil {
+		return s.oauth.Metrics
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package vivawallet
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// newTestAPI creates an API whose clients send their requests to handler. The token
+// endpoint is served on its behalf.
+func newTestAPI(t *testing.T, handler http.HandlerFunc) *API {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/connect/token" {
+			_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600,"token_type":"Bearer"}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	oauth := NewOAuth("client-id", "client-secret", true, WithBaseURL(srv.URL))
+	basic := NewBasicAuth("merchant-id", "api-key", true, WithBaseURL(srv.URL))
+	return NewAPI(oauth, basic)
+}
+
+// recorder records the requests sent to the api, the token requests aside, and answers
+// them with a status and a body.
+type recorder struct {
+	status int
+	body   string
+
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+type recordedRequest struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+func (rec *recorder) handle(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	req := recordedRequest{method: r.Method, path: r.URL.Path}
+	_ = json.Unmarshal(data, &req.body)
+
+	rec.mu.Lock()
+	rec.requests = append(rec.requests, req)
+	rec.mu.Unlock()
+
+	if rec.status != 0 {
+		w.WriteHeader(rec.status)
+	}
+	_, _ = w.Write([]byte(rec.body))
+}
+
+// only returns the single request received, failing the test otherwise.
+func (rec *recorder) only(t *testing.T) recordedRequest {
+	t.Helper()
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.requests) != 1 {
+		t.Fatalf("received %d requests, want 1", len(rec.requests))
+	}
+	return rec.requests[0]
+}
+
+func (rec *recorder) count() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return len(rec.requests)
+}
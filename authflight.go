@@ -0,0 +1,54 @@
+package vivawallet
+
+import (
+	"context"
+	"sync"
+)
+
+// authFlight deduplicates concurrent token requests, so that the goroutines finding an
+// expired token at the same time wait for a single authentication and share its result.
+type authFlight struct {
+	lock sync.Mutex
+	call *authCall
+}
+
+type authCall struct {
+	done     chan struct{}
+	response *TokenResponse
+	err      error
+}
+
+// do runs fn, unless a call is already in flight in which case it shares the result of
+// that call instead. The call runs with the values but without the cancellation of the
+// ctx of the caller starting it, so that the caller giving up does not fail the others.
+// Each caller stops waiting when its own ctx is done.
+func (f *authFlight) do(ctx context.Context, fn func(ctx context.Context) (*TokenResponse, error)) (*TokenResponse, error) {
+	if f == nil {
+		return fn(ctx)
+	}
+
+	f.lock.Lock()
+	c := f.call
+	if c == nil {
+		c = &authCall{done: make(chan struct{})}
+		f.call = c
+		go f.run(context.WithoutCancel(ctx), c, fn)
+	}
+	f.lock.Unlock()
+
+	select {
+	case <-c.done:
+		return c.response, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *authFlight) run(ctx context.Context, c *authCall, fn func(ctx context.Context) (*TokenResponse, error)) {
+	c.response, c.err = fn(ctx)
+
+	f.lock.Lock()
+	f.call = nil
+	f.lock.Unlock()
+	close(c.done)
+}
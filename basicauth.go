@@ -2,79 +2,77 @@ package vivawallet
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 )
 
 // New creates a new viva client for the basic auth apis
-func NewBasicAuth(merchantID string, apiKey string, demo bool) *BasicAuthClient {
+func NewBasicAuth(merchantID string, apiKey string, demo bool, opts ...Option) *BasicAuthClient {
+	o := newClientOptions(opts)
 	return &BasicAuthClient{
 		Config: Config{
 			Demo:       demo,
 			MerchantID: merchantID,
 			APIKey:     apiKey,
+			BaseURL:    o.baseURL,
 		},
-		Client: httpClient,
+		Client:    o.httpClient(),
+		UserAgent: o.userAgent,
 	}
 }
 
-func (c BasicAuthClient) Get(uri string, v interface{}) error {
-	req := newRequest("GET", uri, nil)
-	body, reqErr := c.performReq(req)
-	if reqErr != nil {
-		return reqErr
-	}
+// NewBasicAuthFromConfig creates a new viva client for the basic auth apis from a loaded
+// Config, including its base url. Options given take precedence over the Config.
+func NewBasicAuthFromConfig(c Config, opts ...Option) *BasicAuthClient {
+	opts = append([]Option{WithBaseURL(c.BaseURL)}, opts...)
+	return NewBasicAuth(c.MerchantID, c.APIKey, c.Demo, opts...)
+}
 
-	return json.Unmarshal(body, v)
+func (c BasicAuthClient) Get(uri string, v interface{}) error {
+	return c.do(context.Background(), "GET", uri, nil, v)
 }
 
 func (c BasicAuthClient) Post(uri string, reader *bytes.Reader, v interface{}) error {
-	req := newRequest("POST", uri, reader)
-	body, reqErr := c.performReq(req)
-	if reqErr != nil {
-		return reqErr
-	}
-
-	return json.Unmarshal(body, v)
+	return c.do(context.Background(), "POST", uri, reader, v)
 }
 
 func (c BasicAuthClient) Patch(uri string, reader *bytes.Reader) error {
-	req := newRequest("PATCH", uri, reader)
-	_, reqErr := c.performReq(req)
-	if reqErr != nil {
-		return reqErr
-	}
-
-	return nil
+	return c.do(context.Background(), "PATCH", uri, reader, nil)
 }
 
 func (c BasicAuthClient) Delete(uri string, reader *bytes.Reader, v interface{}) error {
-	req := newRequest("DELETE", uri, reader)
+	return c.do(context.Background(), "DELETE", uri, reader, v)
+}
+
+// do performs the request and decodes the response body into v, unless v is nil.
+func (c BasicAuthClient) do(ctx context.Context, method string, uri string, reader *bytes.Reader, v interface{}) error {
+	req := newRequest(ctx, method, uri, reader)
 	body, reqErr := c.performReq(req)
 	if reqErr != nil {
 		return reqErr
 	}
 
+	if v == nil {
+		return nil
+	}
 	return json.Unmarshal(body, v)
 }
 
 func (c BasicAuthClient) performReq(req *http.Request) ([]byte, error) {
 	req.Header.Add("Content-Type", "application/json")
 	req.SetBasicAuth(c.Config.MerchantID, c.Config.APIKey)
+	setUserAgent(req, c.UserAgent)
 
-	resp, httpErr := c.Client.Do(req)
+	resp, body, httpErr := send(c.Client, c.hooks(), req)
 	if httpErr != nil {
 		return nil, fmt.Errorf("failed to perform request %s", httpErr)
 	}
 
-	defer resp.Body.Close()
-	body, bodyErr := io.ReadAll(resp.Body)
-
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("failed to perform request with status %d", resp.StatusCode)
 	}
 
-	return body, bodyErr
+	return body, nil
 }
@@ -0,0 +1,113 @@
+package vivawallet
+
+import (
+	"context"
+	"fmt"
+)
+
+// StatusFinished is the StatusID of the transactions that completed successfully.
+const StatusFinished = "F"
+
+// CardVerificationOrder is an order storing the card of the customer without charging
+// it, e.g. to charge a subscription later.
+type CardVerificationOrder struct {
+	Customer             Customer
+	CustomerTransactions string
+	MerchantTransactions string
+	SourceCode           string
+	PaymentTimeout       int
+	Tags                 []string
+}
+
+func (o CardVerificationOrder) order() CheckoutOrder {
+	return CheckoutOrder{
+		Amount:               0,
+		IsCardVerification:   true,
+		AllowRecurring:       true,
+		Customer:             o.Customer,
+		CustomerTransactions: o.CustomerTransactions,
+		MerchantTransactions: o.MerchantTransactions,
+		SourceCode:           o.SourceCode,
+		PaymentTimeout:       o.PaymentTimeout,
+		Tags:                 o.Tags,
+	}
+}
+
+// CreateCardVerification creates a zero amount order verifying the card of the customer.
+// Once the customer completes it, the card is saved with Transactions.SaveCard.
+// Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Payments/paths/~1checkout~1v2~1orders/post
+func (s *OrdersService) CreateCardVerification(ctx context.Context, payload CardVerificationOrder) (*CheckoutOrderResponse, error) {
+	return s.create(ctx, "CreateCardVerification", payload.order())
+}
+
+type CreateCardToken struct {
+	TransactionID string `json:"transactionId"`
+}
+
+type CardToken struct {
+	Token string `json:"token"`
+}
+
+// CreateCardToken creates a reusable token for the card used by a transaction, which can
+// be passed in the CardTokens of the orders.
+// Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Card-Tokenization
+func (s *TransactionsService) CreateCardToken(ctx context.Context, payload CreateCardToken) (*CardToken, error) {
+	ctx = withOperation(ctx, "CreateCardToken", Attribute{Key: AttrTransactionID, Value: payload.TransactionID})
+	if payload.TransactionID == "" {
+		return nil, ValidationError{{Field: "transactionId", Message: "must be set"}}
+	}
+
+	c, err := service(*s).oauthClient(ctx, ScopeAcquiring)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := cardTokenUri(c.Config)
+	body, err := jsonBody(payload, "card token")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &CardToken{}
+	reqErr := c.do(ctx, "POST", uri, body, t)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	return t, nil
+}
+
+func cardTokenUri(c Config) string {
+	return fmt.Sprintf("%s/acquiring/v1/cards/tokens", ApiUri(c))
+}
+
+// SavedCard is a card saved by a card verification.
+type SavedCard struct {
+	Token      string
+	CardNumber string
+	CardType   CardType
+	Customer   Customer
+}
+
+// SaveCard fetches the transaction of a completed card verification and creates a
+// reusable token for its card. It fails if the verification did not complete.
+func (s *TransactionsService) SaveCard(ctx context.Context, trxID string) (*SavedCard, error) {
+	trx, err := s.Get(ctx, trxID)
+	if err != nil {
+		return nil, err
+	}
+	if trx.StatusID != StatusFinished {
+		return nil, fmt.Errorf("card verification %s did not complete, status %s", trxID, trx.StatusID)
+	}
+
+	t, err := s.CreateCardToken(ctx, CreateCardToken{TransactionID: trxID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SavedCard{
+		Token:      t.Token,
+		CardNumber: trx.CardNumber,
+		CardType:   trx.CardType(),
+		Customer:   trx.Customer(),
+	}, nil
+}
@@ -0,0 +1,138 @@
+package vivawallet
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreateCardVerification(t *testing.T) {
+	rec := &recorder{body: `{"orderCode":1272214778972604}`}
+	api := newTestAPI(t, rec.handle)
+
+	r, err := api.Orders.CreateCardVerification(context.Background(), CardVerificationOrder{
+		Customer:             Customer{Email: "user@example.com"},
+		MerchantTransactions: "customer-1",
+		SourceCode:           "1234",
+		Tags:                 []string{"subscription"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.OrderCode != 1272214778972604 {
+		t.Errorf("CreateCardVerification() = %+v", r)
+	}
+
+	req := rec.only(t)
+	if req.method != "POST" || req.path != "/checkout/v2/orders" {
+		t.Errorf("sent %s %s", req.method, req.path)
+	}
+	want := map[string]interface{}{
+		"amount":             float64(0),
+		"isCardVerification": true,
+		"allowRecurring":     true,
+		"customer":           map[string]interface{}{"email": "user@example.com"},
+		"merchantTrns":       "customer-1",
+		"sourceCode":         "1234",
+		"tags":               []interface{}{"subscription"},
+	}
+	if !reflect.DeepEqual(req.body, want) {
+		t.Errorf("sent %v, want %v", req.body, want)
+	}
+}
+
+func TestCreateCardToken(t *testing.T) {
+	rec := &recorder{body: `{"token":"card-token"}`}
+	api := newTestAPI(t, rec.handle)
+
+	r, err := api.Transactions.CreateCardToken(context.Background(), CreateCardToken{TransactionID: "trx-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Token != "card-token" {
+		t.Errorf("CreateCardToken() = %+v", r)
+	}
+
+	req := rec.only(t)
+	if req.method != "POST" || req.path != "/acquiring/v1/cards/tokens" {
+		t.Errorf("sent %s %s", req.method, req.path)
+	}
+	if want := map[string]interface{}{"transactionId": "trx-1"}; !reflect.DeepEqual(req.body, want) {
+		t.Errorf("sent %v, want %v", req.body, want)
+	}
+}
+
+func TestCreateCardTokenErrors(t *testing.T) {
+	t.Run("validation", func(t *testing.T) {
+		rec := &recorder{}
+		api := newTestAPI(t, rec.handle)
+		_, err := api.Transactions.CreateCardToken(context.Background(), CreateCardToken{})
+		if got := invalidFields(t, err); !reflect.DeepEqual(got, []string{"transactionId"}) {
+			t.Errorf("invalid fields %v, want transactionId", got)
+		}
+		if rec.count() != 0 {
+			t.Errorf("sent %d invalid requests", rec.count())
+		}
+	})
+
+	t.Run("status", func(t *testing.T) {
+		rec := &recorder{status: http.StatusNotFound}
+		api := newTestAPI(t, rec.handle)
+		if _, err := api.Transactions.CreateCardToken(context.Background(), CreateCardToken{TransactionID: "trx-1"}); err == nil || !strings.Contains(err.Error(), "status 404") {
+			t.Errorf("error %v, want the status", err)
+		}
+	})
+}
+
+// cardServer serves a card verification transaction with the given status and the
+// tokens of its card.
+func cardServer(t *testing.T, status string, tokens *int) *API {
+	t.Helper()
+	return newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/checkout/v2/transactions/trx-1":
+			_, _ = w.Write([]byte(`{"statusId":"` + status + `","cardNumber":"411111XXXXXX1111","cardTypeId":0,"email":"user@example.com","fullName":"Jane Doe"}`))
+		case r.Method == "POST" && r.URL.Path == "/acquiring/v1/cards/tokens":
+			*tokens++
+			_, _ = w.Write([]byte(`{"token":"card-token"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestSaveCard(t *testing.T) {
+	var tokens int
+	api := cardServer(t, StatusFinished, &tokens)
+
+	card, err := api.Transactions.SaveCard(context.Background(), "trx-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &SavedCard{
+		Token:      "card-token",
+		CardNumber: "411111XXXXXX1111",
+		CardType:   CardTypeVisa,
+		Customer:   Customer{Email: "user@example.com", FullName: "Jane Doe"},
+	}
+	if !reflect.DeepEqual(card, want) || tokens != 1 {
+		t.Errorf("SaveCard() = %+v after %d token requests, want %+v after 1", card, tokens, want)
+	}
+}
+
+func TestSaveCardErrors(t *testing.T) {
+	var tokens int
+	api := cardServer(t, "E", &tokens)
+	if _, err := api.Transactions.SaveCard(context.Background(), "trx-1"); err == nil || !strings.Contains(err.Error(), "did not complete") {
+		t.Errorf("SaveCard() of a failed verification returned %v", err)
+	}
+	if tokens != 0 {
+		t.Errorf("requested %d tokens for a failed verification", tokens)
+	}
+
+	if _, err := api.Transactions.SaveCard(context.Background(), "trx-2"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("SaveCard() of a missing transaction returned %v", err)
+	}
+}
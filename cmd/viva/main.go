@@ -0,0 +1,210 @@
+// Command viva performs common operations on the Viva API from the command line.
+//
+// The credentials are read from the VIVA_CLIENT_ID, VIVA_CLIENT_SECRET,
+// VIVA_MERCHANT_ID and VIVA_API_KEY environment variables, or the files named by the
+// same variables suffixed with _FILE. The flags may follow the arguments, those after
+// -- are all arguments.
+//
+//	viva [--demo] [--output json|table] <command> <action> [flags] [args]
+//
+//	viva orders create --amount 1000
+//	viva orders get|cancel <orderCode>
+//	viva orders update <orderCode> --amount 1200
+//	viva transactions get <transactionId>
+//	viva transactions cancel|capture <transactionId> --amount 1000
+//	viva wallets list
+//	viva wallets transfer <walletId> <targetWalletId> --amount 1000
+//	viva token
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+const usage = `usage: viva [--demo] [--output json|table] <command> <action> [flags] [args]
+
+commands:
+  orders create|get|update|cancel
+  transactions get|cancel|capture
+  wallets list|transfer
+  token
+`
+
+const userAgent = "viva-cli"
+
+// options are the flags accepted by every command.
+type options struct {
+	demo   bool
+	output string
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.demo, "demo", o.demo, "use the demo environment")
+	fs.StringVar(&o.output, "output", o.output, "output format, json or table")
+}
+
+type command func(ctx context.Context, env *env, args []string) error
+
+// action is a command along with the credentials it needs.
+type action struct {
+	run  command
+	auth vivawallet.AuthMode
+}
+
+var commands = map[string]map[string]action{
+	"orders": {
+		"create": {createOrder, vivawallet.AuthOAuth},
+		"get":    {getOrder, vivawallet.AuthBasic},
+		"update": {updateOrder, vivawallet.AuthBasic},
+		"cancel": {cancelOrder, vivawallet.AuthBasic},
+	},
+	"transactions": {
+		"get":     {getTransaction, vivawallet.AuthOAuth},
+		"cancel":  {cancelTransaction, vivawallet.AuthBasic},
+		"capture": {captureTransaction, vivawallet.AuthBasic},
+	},
+	"wallets": {
+		"list":     {listWallets, vivawallet.AuthBasic},
+		"transfer": {transferBalance, vivawallet.AuthBasic},
+	},
+}
+
+// tokenAction is the token command, which has no action.
+var tokenAction = action{token, vivawallet.AuthOAuth}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	opts := &options{output: "table"}
+
+	fs := flag.NewFlagSet("viva", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	opts.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	name, a, args, err := dispatch(fs.Args())
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+
+	config, err := loadConfig(a.auth)
+	if err != nil {
+		return err
+	}
+	return a.run(ctx, &env{opts: opts, name: name, config: config}, args)
+}
+
+// dispatch finds the action of the arguments and returns its name and its arguments.
+func dispatch(args []string) (string, action, []string, error) {
+	if len(args) == 0 {
+		return "", action{}, nil, fmt.Errorf("missing command")
+	}
+	if args[0] == "token" {
+		return "token", tokenAction, args[1:], nil
+	}
+
+	actions, ok := commands[args[0]]
+	if !ok {
+		return "", action{}, nil, fmt.Errorf("unknown command %s", args[0])
+	}
+	if len(args) < 2 {
+		return "", action{}, nil, fmt.Errorf("missing action for %s", args[0])
+	}
+	a, ok := actions[args[1]]
+	if !ok {
+		return "", action{}, nil, fmt.Errorf("unknown action %s %s", args[0], args[1])
+	}
+	return args[0] + " " + args[1], a, args[2:], nil
+}
+
+// env is what a command runs with, the clients being created once its flags are parsed.
+type env struct {
+	opts   *options
+	name   string
+	config vivawallet.Config
+	api    *vivawallet.API
+}
+
+// parse parses the flags of a command, along with the common ones, and creates the
+// clients. It returns the positional arguments, which may come before the flags.
+func (e *env) parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	e.opts.register(fs)
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+
+	// --demo overrides VIVA_DEMO.
+	e.config.Demo = e.config.Demo || e.opts.demo
+
+	oauthClient := vivawallet.NewOAuthFromConfig(e.config, vivawallet.WithUserAgent(userAgent))
+	basicAuthClient := vivawallet.NewBasicAuthFromConfig(e.config, vivawallet.WithUserAgent(userAgent))
+	e.api = vivawallet.NewAPI(oauthClient, basicAuthClient)
+	return args, nil
+}
+
+// parseFlags parses the flags wherever they are among the arguments, unlike
+// flag.FlagSet.Parse which stops at the first positional argument, and returns the
+// positional arguments. The arguments following -- are positional, even those starting
+// with a dash.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if parsed := len(args) - len(rest); parsed > 0 && args[parsed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// loadConfig reads the credentials from the environment and checks that those needed by
+// the command are set.
+func loadConfig(auth vivawallet.AuthMode) (vivawallet.Config, error) {
+	config, err := vivawallet.LoadConfigEnv(vivawallet.DefaultEnvPrefix)
+	if err != nil {
+		return vivawallet.Config{}, err
+	}
+	if err := config.Validate(auth); err != nil {
+		return vivawallet.Config{}, err
+	}
+	return config, nil
+}
+
+func (e *env) flags() *flag.FlagSet {
+	return flag.NewFlagSet(e.name, flag.ContinueOnError)
+}
+
+func token(ctx context.Context, e *env, args []string) error {
+	fs := e.flags()
+	if _, err := e.parse(fs, args); err != nil {
+		return err
+	}
+
+	oauthClient := vivawallet.NewOAuthFromConfig(e.config, vivawallet.WithUserAgent(userAgent))
+	t, err := oauthClient.Authenticate()
+	if err != nil {
+		return err
+	}
+	return render(e.opts, t)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		positional []string
+		amount     int64
+		demo       bool
+	}{
+		{"flags first", []string{"--amount", "100", "1234"}, []string{"1234"}, 100, false},
+		{"flags last", []string{"1234", "--amount=100", "--demo"}, []string{"1234"}, 100, true},
+		{"flags between", []string{"a", "--amount", "100", "b"}, []string{"a", "b"}, 100, false},
+		{"no flags", []string{"a", "b"}, []string{"a", "b"}, 0, false},
+		{"no arguments", nil, nil, 0, false},
+		{"terminator", []string{"--amount", "100", "--", "--demo", "-1"}, []string{"--demo", "-1"}, 100, false},
+		{"terminator after positional", []string{"a", "--", "-b", "--amount", "5"}, []string{"a", "-b", "--amount", "5"}, 0, false},
+		{"terminator only", []string{"--"}, nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var amount int64
+			opts := &options{}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.Int64Var(&amount, "amount", 0, "")
+			opts.register(fs)
+
+			positional, err := parseFlags(fs, tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(positional, tt.positional) || amount != tt.amount || opts.demo != tt.demo {
+				t.Errorf("parseFlags() = %q with amount %d and demo %t, want %q with %d and %t",
+					positional, amount, opts.demo, tt.positional, tt.amount, tt.demo)
+			}
+		})
+	}
+}
+
+func TestParseFlagsUnknownFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(nopWriter{})
+	if _, err := parseFlags(fs, []string{"a", "--unknown"}); err == nil {
+		t.Error("parseFlags() succeeded, want an unknown flag error")
+	}
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		args []string
+		name string
+		auth vivawallet.AuthMode
+		rest []string
+		err  string
+	}{
+		{args: []string{"orders", "create", "--amount", "100"}, name: "orders create", auth: vivawallet.AuthOAuth, rest: []string{"--amount", "100"}},
+		{args: []string{"orders", "get", "1234"}, name: "orders get", auth: vivawallet.AuthBasic, rest: []string{"1234"}},
+		{args: []string{"transactions", "get", "id"}, name: "transactions get", auth: vivawallet.AuthOAuth, rest: []string{"id"}},
+		{args: []string{"wallets", "list"}, name: "wallets list", auth: vivawallet.AuthBasic, rest: []string{}},
+		{args: []string{"token"}, name: "token", auth: vivawallet.AuthOAuth, rest: []string{}},
+		{args: nil, err: "missing command"},
+		{args: []string{"refunds"}, err: "unknown command refunds"},
+		{args: []string{"orders"}, err: "missing action for orders"},
+		{args: []string{"orders", "delete"}, err: "unknown action orders delete"},
+	}
+	for _, tt := range tests {
+		name, a, rest, err := dispatch(tt.args)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("dispatch(%q) error %v, want %s", tt.args, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("dispatch(%q) failed: %s", tt.args, err)
+			continue
+		}
+		if name != tt.name || a.auth != tt.auth || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("dispatch(%q) = %s, %d, %q, want %s, %d, %q", tt.args, name, a.auth, rest, tt.name, tt.auth, tt.rest)
+		}
+	}
+}
+
+func setCredentials(t *testing.T, oauth, basic bool) {
+	t.Helper()
+	values := map[string]string{
+		"VIVA_CLIENT_ID":     "client-id",
+		"VIVA_CLIENT_SECRET": "client-secret",
+		"VIVA_MERCHANT_ID":   "merchant-id",
+		"VIVA_API_KEY":       "api-key",
+	}
+	for name, value := range values {
+		t.Setenv(name+"_FILE", "")
+		isOAuth := name == "VIVA_CLIENT_ID" || name == "VIVA_CLIENT_SECRET"
+		if (isOAuth && !oauth) || (!isOAuth && !basic) {
+			value = ""
+		}
+		t.Setenv(name, value)
+	}
+	t.Setenv("VIVA_DEMO", "")
+}
+
+func TestRunValidatesConfig(t *testing.T) {
+	tests := []struct {
+		args         []string
+		oauth, basic bool
+		err          string
+	}{
+		{[]string{"wallets", "list"}, true, false, "missing config MerchantID, APIKey"},
+		{[]string{"orders", "create"}, false, true, "missing config ClientID, ClientSecret"},
+		{[]string{"token"}, false, true, "missing config ClientID, ClientSecret"},
+		{[]string{"transactions", "get"}, false, false, "missing config ClientID, ClientSecret"},
+		// The commands run once their credentials are set.
+		{[]string{"orders", "get", "abc"}, false, true, "invalid order code abc"},
+		{[]string{"transactions", "get"}, true, false, "expected a transaction id"},
+		{[]string{"wallets", "transfer", "--", "-1"}, false, true, "expected a wallet id and a target wallet id"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			setCredentials(t, tt.oauth, tt.basic)
+			err := run(context.Background(), tt.args)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("run(%q) error %v, want %s", tt.args, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+func createOrder(ctx context.Context, e *env, args []string) error {
+	order := vivawallet.CheckoutOrder{}
+
+	fs := e.flags()
+	fs.Int64Var(&order.Amount, "amount", 0, "amount in cents")
+	fs.StringVar(&order.CustomerTransactions, "customer-trns", "", "description shown to the customer")
+	fs.StringVar(&order.MerchantTransactions, "merchant-trns", "", "merchant reference")
+	fs.StringVar(&order.SourceCode, "source-code", "", "payment source code")
+	fs.StringVar(&order.Customer.Email, "email", "", "email of the customer")
+	fs.StringVar(&order.Customer.FullName, "full-name", "", "full name of the customer")
+	fs.BoolVar(&order.PreAuth, "preauth", false, "create a pre-authorization")
+	args, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	r, err := e.api.Orders.Create(ctx, order)
+	if err != nil {
+		return err
+	}
+	return render(e.opts, r)
+}
+
+func getOrder(ctx context.Context, e *env, args []string) error {
+	fs := e.flags()
+	args, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	orderCode, err := orderCodeArg(args)
+	if err != nil {
+		return err
+	}
+
+	r, err := e.api.Orders.Get(ctx, orderCode)
+	if err != nil {
+		return err
+	}
+	return render(e.opts, r)
+}
+
+func updateOrder(ctx context.Context, e *env, args []string) error {
+	update := vivawallet.UpdateOrderPayment{}
+
+	fs := e.flags()
+	fs.Int64Var(&update.Amount, "amount", 0, "amount in cents")
+	fs.StringVar(&update.ExpirationDate, "expiration-date", "", "expiration date, e.g. 2024-12-31T23:59:59")
+	fs.BoolVar(&update.DisablePaidState, "disable-paid-state", false, "allow paying the order more than once")
+	args, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	orderCode, err := orderCodeArg(args)
+	if err != nil {
+		return err
+	}
+
+	if err := e.api.Orders.Update(ctx, orderCode, update); err != nil {
+		return err
+	}
+	return render(e.opts, map[string]interface{}{"orderCode": orderCode, "updated": true})
+}
+
+func cancelOrder(ctx context.Context, e *env, args []string) error {
+	fs := e.flags()
+	args, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	orderCode, err := orderCodeArg(args)
+	if err != nil {
+		return err
+	}
+
+	r, err := e.api.Orders.Cancel(ctx, orderCode)
+	if err != nil {
+		return err
+	}
+	return render(e.opts, r)
+}
+
+func orderCodeArg(args []string) (int64, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("expected an order code")
+	}
+	orderCode, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid order code %s", args[0])
+	}
+	return orderCode, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// render writes v to the standard output in the format of the options.
+func render(opts *options, v interface{}) error {
+	switch opts.output {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		printTable(w, reflect.ValueOf(v))
+		return w.Flush()
+	}
+	return fmt.Errorf("unknown output %s", opts.output)
+}
+
+// printTable writes a list as a table with a row per element, and anything else as a
+// table of its fields.
+func printTable(w *tabwriter.Writer, v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return
+		}
+		names, _ := columns(v.Index(0))
+		fmt.Fprintln(w, strings.Join(names, "\t"))
+		for i := 0; i < v.Len(); i++ {
+			_, values := columns(v.Index(i))
+			fmt.Fprintln(w, strings.Join(values, "\t"))
+		}
+	default:
+		names, values := columns(v)
+		for i := range names {
+			fmt.Fprintf(w, "%s\t%s\n", names[i], values[i])
+		}
+	}
+}
+
+func columns(v reflect.Value) ([]string, []string) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	var names, values []string
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			names = append(names, t.Field(i).Name)
+			values = append(values, fmt.Sprint(v.Field(i).Interface()))
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			names = append(names, fmt.Sprint(k.Interface()))
+			values = append(values, fmt.Sprint(v.MapIndex(k).Interface()))
+		}
+	default:
+		names = append(names, "value")
+		values = append(values, fmt.Sprint(v.Interface()))
+	}
+	return names, values
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+func getTransaction(ctx context.Context, e *env, args []string) error {
+	fs := e.flags()
+	args, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	id, err := transactionArg(args)
+	if err != nil {
+		return err
+	}
+
+	r, err := e.api.Transactions.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return render(e.opts, r)
+}
+
+func cancelTransaction(ctx context.Context, e *env, args []string) error {
+	opts := vivawallet.CancelOptions{}
+
+	fs := e.flags()
+	fs.Int64Var(&opts.Amount, "amount", 0, "amount to cancel or refund in cents")
+	fs.StringVar(&opts.SourceCode, "source-code", "", "payment source code")
+	args, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	id, err := transactionArg(args)
+	if err != nil {
+		return err
+	}
+
+	r, err := e.api.Transactions.Cancel(ctx, id, opts)
+	if err != nil {
+		return err
+	}
+	return render(e.opts, r)
+}
+
+// captureTransaction captures a pre-authorization by creating a transaction on it.
+func captureTransaction(ctx context.Context, e *env, args []string) error {
+	payload := vivawallet.CreateTransaction{}
+
+	fs := e.flags()
+	fs.Int64Var(&payload.Amount, "amount", 0, "amount to capture in cents")
+	fs.StringVar(&payload.MerchantTrns, "merchant-trns", "", "merchant reference")
+	args, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	id, err := transactionArg(args)
+	if err != nil {
+		return err
+	}
+
+	r, err := e.api.Transactions.Create(ctx, id, payload)
+	if err != nil {
+		return err
+	}
+	return render(e.opts, r)
+}
+
+func transactionArg(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected a transaction id")
+	}
+	return args[0], nil
+}
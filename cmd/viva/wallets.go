@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+func listWallets(ctx context.Context, e *env, args []string) error {
+	fs := e.flags()
+	args, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	r, err := e.api.Wallets.List(ctx)
+	if err != nil {
+		return err
+	}
+	return render(e.opts, r)
+}
+
+func transferBalance(ctx context.Context, e *env, args []string) error {
+	payload := vivawallet.BalanceTransfer{}
+
+	fs := e.flags()
+	fs.IntVar(&payload.Amount, "amount", 0, "amount to transfer in cents")
+	fs.StringVar(&payload.Description, "description", "", "description of the transfer")
+	fs.StringVar(&payload.SaleTransactionID, "sale-transaction-id", "", "sale transaction the transfer relates to")
+	args, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("expected a wallet id and a target wallet id")
+	}
+
+	r, err := e.api.Wallets.Transfer(ctx, args[0], args[1], payload)
+	if err != nil {
+		return err
+	}
+	return render(e.opts, r)
+}
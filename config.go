@@ -0,0 +1,281 @@
+package vivawallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AuthMode is the authentication a Config is expected to support.
+type AuthMode int
+
+const (
+	AuthOAuth AuthMode = 1 << iota
+	AuthBasic
+)
+
+// String hides the secrets of the config, so that it can be logged safely.
+func (c Config) String() string {
+	return fmt.Sprintf("{Demo:%t ClientID:%s ClientSecret:%s MerchantID:%s APIKey:%s Scopes:%v}",
+		c.Demo, c.ClientID, mask(c.ClientSecret), c.MerchantID, mask(c.APIKey), c.Scopes)
+}
+
+// GoString hides the secrets of the config from the %#v format.
+func (c Config) GoString() string {
+	return "vivawallet.Config" + c.String()
+}
+
+// LogValue hides the secrets of the config from the slog loggers.
+func (c Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Bool("demo", c.Demo),
+		slog.String("client_id", c.ClientID),
+		slog.String("client_secret", mask(c.ClientSecret)),
+		slog.String("merchant_id", c.MerchantID),
+		slog.String("api_key", mask(c.APIKey)),
+		slog.Any("scopes", c.Scopes),
+	)
+}
+
+func mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// Validate checks that the fields required by the given authentication modes are set.
+func (c Config) Validate(modes AuthMode) error {
+	var missing []string
+	if modes&AuthOAuth != 0 {
+		if c.ClientID == "" {
+			missing = append(missing, "ClientID")
+		}
+		if c.ClientSecret == "" {
+			missing = append(missing, "ClientSecret")
+		}
+	}
+	if modes&AuthBasic != 0 {
+		if c.MerchantID == "" {
+			missing = append(missing, "MerchantID")
+		}
+		if c.APIKey == "" {
+			missing = append(missing, "APIKey")
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing config %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// fileConfig is the layout of the config files and the names of the secret files.
+type fileConfig struct {
+	Demo         *bool    `json:"demo" yaml:"demo"`
+	ClientID     string   `json:"client_id" yaml:"client_id"`
+	ClientSecret string   `json:"client_secret" yaml:"client_secret"`
+	MerchantID   string   `json:"merchant_id" yaml:"merchant_id"`
+	APIKey       string   `json:"api_key" yaml:"api_key"`
+	Scopes       []string `json:"scopes" yaml:"scopes"`
+}
+
+// merge sets the fields of the config that are set in f.
+func (f fileConfig) merge(c *Config) {
+	if f.Demo != nil {
+		c.Demo = *f.Demo
+	}
+	set(&c.ClientID, f.ClientID)
+	set(&c.ClientSecret, f.ClientSecret)
+	set(&c.MerchantID, f.MerchantID)
+	set(&c.APIKey, f.APIKey)
+	if len(f.Scopes) > 0 {
+		c.Scopes = f.Scopes
+	}
+}
+
+func set(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
+
+// LoadConfigFile reads a yaml or json config file, depending on its extension, with the
+// keys demo, client_id, client_secret, merchant_id, api_key and scopes.
+func LoadConfigFile(path string) (Config, error) {
+	c := Config{}
+	if err := mergeFile(&c, path); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+func mergeFile(c *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config %s", err)
+	}
+
+	f := fileConfig{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &f)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &f)
+	default:
+		return fmt.Errorf("unknown config format %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config %s", err)
+	}
+
+	f.merge(c)
+	return nil
+}
+
+// LoadConfigSecrets reads the config from a directory of secret files, e.g. a mounted
+// Kubernetes secret, with a file per key named like the keys of LoadConfigFile. Missing
+// files are skipped.
+func LoadConfigSecrets(dir string) (Config, error) {
+	c := Config{}
+	if err := mergeSecrets(&c, dir); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+func mergeSecrets(c *Config, dir string) error {
+	f := fileConfig{}
+	fields := map[string]*string{
+		"client_id":     &f.ClientID,
+		"client_secret": &f.ClientSecret,
+		"merchant_id":   &f.MerchantID,
+		"api_key":       &f.APIKey,
+	}
+	for name, field := range fields {
+		v, err := readSecret(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		*field = v
+	}
+
+	f.merge(c)
+	return nil
+}
+
+// readSecret reads a secret file, ignoring the trailing new line. A missing file is an
+// empty secret.
+func readSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// LoadConfigEnv reads the config from the environment variables with the given prefix,
+// e.g. VIVA_CLIENT_ID, VIVA_CLIENT_SECRET, VIVA_MERCHANT_ID, VIVA_API_KEY, VIVA_DEMO and
+// VIVA_SCOPES for the prefix VIVA. The secrets may also be read from the file named by
+// the variable suffixed with _FILE, e.g. VIVA_API_KEY_FILE.
+func LoadConfigEnv(prefix string) (Config, error) {
+	c := Config{}
+	if err := mergeEnv(&c, prefix); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+func mergeEnv(c *Config, prefix string) error {
+	name := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "_" + key
+	}
+
+	f := fileConfig{}
+	fields := map[string]*string{
+		"CLIENT_ID":     &f.ClientID,
+		"CLIENT_SECRET": &f.ClientSecret,
+		"MERCHANT_ID":   &f.MerchantID,
+		"API_KEY":       &f.APIKey,
+	}
+	for key, field := range fields {
+		if path := os.Getenv(name(key + "_FILE")); path != "" {
+			v, err := readSecret(path)
+			if err != nil {
+				return err
+			}
+			*field = v
+		}
+		set(field, os.Getenv(name(key)))
+	}
+
+	if v := os.Getenv(name("DEMO")); v != "" {
+		demo, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %s", name("DEMO"), v)
+		}
+		f.Demo = &demo
+	}
+	if v := os.Getenv(name("SCOPES")); v != "" {
+		f.Scopes = strings.Fields(strings.ReplaceAll(v, ",", " "))
+	}
+
+	f.merge(c)
+	return nil
+}
+
+// LoadOptions are the sources of LoadConfig. Empty sources are skipped.
+type LoadOptions struct {
+	File       string
+	SecretsDir string
+	EnvPrefix  string
+	// Require lists the authentication modes the config must support.
+	Require AuthMode
+}
+
+// DefaultEnvPrefix is the prefix of the environment variables read by LoadConfig when
+// none is set.
+const DefaultEnvPrefix = "VIVA"
+
+// LoadConfig reads the config from a file, then from a secrets directory and then from
+// the environment, each source overriding the fields set by the previous ones, and
+// validates it.
+func LoadConfig(opts LoadOptions) (Config, error) {
+	c := Config{}
+	if opts.File != "" {
+		if err := mergeFile(&c, opts.File); err != nil {
+			return Config{}, err
+		}
+	}
+	if opts.SecretsDir != "" {
+		if err := mergeSecrets(&c, opts.SecretsDir); err != nil {
+			return Config{}, err
+		}
+	}
+
+	prefix := opts.EnvPrefix
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	if err := mergeEnv(&c, prefix); err != nil {
+		return Config{}, err
+	}
+
+	if err := c.Validate(opts.Require); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
@@ -0,0 +1,157 @@
+package vivawallet
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigMasksSecrets(t *testing.T) {
+	c := Config{ClientID: "client-id", ClientSecret: "client-secret", MerchantID: "merchant-id", APIKey: "api-key", Scopes: []string{ScopeRedirectCheckout}}
+
+	var logs bytes.Buffer
+	slog.New(slog.NewJSONHandler(&logs, nil)).Info("loaded", "config", c)
+
+	outputs := map[string]string{
+		"%v":   fmt.Sprintf("%v", c),
+		"%+v":  fmt.Sprintf("%+v", c),
+		"%#v":  fmt.Sprintf("%#v", c),
+		"%s":   fmt.Sprintf("%s", c),
+		"slog": logs.String(),
+	}
+	for format, out := range outputs {
+		if strings.Contains(out, "client-secret") || strings.Contains(out, "api-key") {
+			t.Errorf("%s leaks the secrets: %s", format, out)
+		}
+		if !strings.Contains(out, "client-id") || !strings.Contains(out, "merchant-id") || !strings.Contains(out, redacted) {
+			t.Errorf("%s = %s, want the ids and the masked secrets", format, out)
+		}
+	}
+
+	logs.Reset()
+	slog.New(slog.NewJSONHandler(&logs, nil)).Info("loaded", "config", Config{ClientID: "client-id"})
+	if strings.Contains(logs.String(), redacted) {
+		t.Errorf("empty secrets are masked: %s", logs.String())
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	want := Config{Demo: true, ClientID: "client-id", ClientSecret: "client-secret", Scopes: []string{"a", "b"}}
+
+	tests := map[string]string{
+		"config.yaml": "demo: true\nclient_id: client-id\nclient_secret: client-secret\nscopes: [a, b]\n",
+		"config.json": `{"demo":true,"client_id":"client-id","client_secret":"client-secret","scopes":["a","b"]}`,
+	}
+	for name, content := range tests {
+		c, err := LoadConfigFile(writeFile(t, dir, name, content))
+		if err != nil {
+			t.Fatalf("LoadConfigFile(%s) failed: %s", name, err)
+		}
+		if !reflect.DeepEqual(c, want) {
+			t.Errorf("LoadConfigFile(%s) = %+v, want %+v", name, c, want)
+		}
+	}
+
+	invalid := map[string]string{
+		"config.toml": "demo = true",
+		"bad.json":    "{",
+		"bad.yaml":    "scopes: {",
+	}
+	for name, content := range invalid {
+		if _, err := LoadConfigFile(writeFile(t, dir, name, content)); err == nil {
+			t.Errorf("LoadConfigFile(%s) succeeded, want an error", name)
+		}
+	}
+	if _, err := LoadConfigFile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("LoadConfigFile() of a missing file succeeded, want an error")
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, dir, "config.yaml",
+		"demo: true\nclient_id: file-client\nclient_secret: file-secret\nmerchant_id: file-merchant\napi_key: file-key\nscopes: [file]\n")
+
+	secrets := filepath.Join(dir, "secrets")
+	if err := os.Mkdir(secrets, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, secrets, "client_secret", "secrets-secret\n")
+	writeFile(t, secrets, "api_key", "secrets-key\n")
+
+	t.Setenv("TEST_VIVA_API_KEY", "env-key")
+	t.Setenv("TEST_VIVA_MERCHANT_ID", "env-merchant")
+	t.Setenv("TEST_VIVA_DEMO", "false")
+	t.Setenv("TEST_VIVA_SCOPES", "one, two three")
+
+	c, err := LoadConfig(LoadOptions{File: file, SecretsDir: secrets, EnvPrefix: "TEST_VIVA", Require: AuthOAuth | AuthBasic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{
+		ClientID:     "file-client",
+		ClientSecret: "secrets-secret",
+		MerchantID:   "env-merchant",
+		APIKey:       "env-key",
+		Scopes:       []string{"one", "two", "three"},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("LoadConfig() = %#v, want %#v", c, want)
+	}
+}
+
+func TestLoadConfigEnvFileSecrets(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TEST_VIVA_CLIENT_ID", "env-client")
+	t.Setenv("TEST_VIVA_CLIENT_SECRET_FILE", writeFile(t, dir, "client_secret", "file-secret\r\n"))
+	t.Setenv("TEST_VIVA_API_KEY_FILE", writeFile(t, dir, "api_key", "file-key"))
+	t.Setenv("TEST_VIVA_API_KEY", "env-key")
+	t.Setenv("TEST_VIVA_MERCHANT_ID_FILE", filepath.Join(dir, "missing"))
+
+	c, err := LoadConfigEnv("TEST_VIVA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{ClientID: "env-client", ClientSecret: "file-secret", APIKey: "env-key"}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("LoadConfigEnv() = %#v, want %#v", c, want)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	t.Run("missing fields", func(t *testing.T) {
+		t.Setenv("TEST_VIVA_CLIENT_ID", "env-client")
+		_, err := LoadConfig(LoadOptions{EnvPrefix: "TEST_VIVA", Require: AuthOAuth | AuthBasic})
+		if err == nil || err.Error() != "missing config ClientSecret, MerchantID, APIKey" {
+			t.Errorf("LoadConfig() error %v, want the missing fields", err)
+		}
+	})
+
+	t.Run("invalid demo", func(t *testing.T) {
+		t.Setenv("TEST_VIVA_DEMO", "maybe")
+		if _, err := LoadConfig(LoadOptions{EnvPrefix: "TEST_VIVA"}); err == nil {
+			t.Error("LoadConfig() succeeded, want an invalid demo error")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := LoadConfig(LoadOptions{File: filepath.Join(t.TempDir(), "missing.yaml")}); err == nil {
+			t.Error("LoadConfig() succeeded, want a missing file error")
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package vivawallet
+
+import (
+	"sync"
+)
+
+// Customer holds the details of the customer of a payment. They prefill the payment
+// form and appear in the transactions.
+type Customer struct {
+	Email       string `json:"email,omitempty"`
+	FullName    string `json:"fullName,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+	CountryCode string `json:"countryCode,omitempty"`
+	RequestLang string `json:"requestLang,omitempty"`
+	Locale      string `json:"locale,omitempty"`
+}
+
+// RequestLangs lists the languages of the payment form.
+var RequestLangs = []string{
+	"bg-BG", "cs-CZ", "da-DK", "de-DE", "el-GR", "en-GB", "en-US", "es-ES", "et-EE",
+	"fi-FI", "fr-FR", "hr-HR", "hu-HU", "it-IT", "lt-LT", "lv-LV", "nl-NL", "pl-PL",
+	"pt-PT", "ro-RO", "ru-RU", "sk-SK", "sv-SE",
+}
+
+// Validate checks the customer before it is sent to Viva.
+func (c Customer) Validate() error {
+	v := &validator{}
+	c.validate(v, "")
+	return v.err()
+}
+
+// validate checks the fields of the customer, naming them after the given prefix.
+func (c Customer) validate(v *validator, prefix string) {
+	v.email(prefix+"email", c.Email)
+	v.phone(prefix+"phone", c.Phone)
+	v.country(prefix+"countryCode", c.CountryCode)
+	v.requestLang(prefix+"requestLang", c.RequestLang)
+}
+
+// Customer returns the details of the customer who paid the transaction.
+func (t GetTransactionResponse) Customer() Customer {
+	return Customer{Email: t.Email, FullName: t.FullName}
+}
+
+// Customer returns the details of the customer who paid the transaction.
+func (t TransactionEventData) Customer() Customer {
+	return Customer{Email: t.Email, FullName: t.FullName}
+}
+
+// CustomerProfile links a customer of your own to the card tokens saved for them, so
+// that they can pay again without entering their card.
+type CustomerProfile struct {
+	ID         string   `json:"id"`
+	Customer   Customer `json:"customer"`
+	CardTokens []string `json:"cardTokens,omitempty"`
+}
+
+// AddCardToken links a card token to the profile, unless it is linked already.
+func (p *CustomerProfile) AddCardToken(token string) {
+	for _, t := range p.CardTokens {
+		if t == token {
+			return
+		}
+	}
+	p.CardTokens = append(p.CardTokens, token)
+}
+
+// RemoveCardToken unlinks a card token from the profile. The tokens are copied, so that
+// a slice the profile was created with is left untouched.
+func (p *CustomerProfile) RemoveCardToken(token string) {
+	var tokens []string
+	for _, t := range p.CardTokens {
+		if t != token {
+			tokens = append(tokens, t)
+		}
+	}
+	p.CardTokens = tokens
+}
+
+// Order returns an order of the given amount for the customer, offering the saved cards
+// on the payment form.
+func (p CustomerProfile) Order(amount int64) CheckoutOrder {
+	return CheckoutOrder{
+		Amount:     amount,
+		Customer:   p.Customer,
+		CardTokens: append([]string(nil), p.CardTokens...),
+	}
+}
+
+// CustomerStore persists the customer profiles. Load returns a nil profile and no error
+// when there is no profile with the id.
+type CustomerStore interface {
+	Load(id string) (*CustomerProfile, error)
+	Store(p CustomerProfile) error
+}
+
+// MemoryCustomerStore keeps the customer profiles in memory.
+type MemoryCustomerStore struct {
+	lock     sync.RWMutex
+	profiles map[string]CustomerProfile
+}
+
+// NewMemoryCustomerStore creates an empty in-memory customer store.
+func NewMemoryCustomerStore() *MemoryCustomerStore {
+	return &MemoryCustomerStore{
+		profiles: map[string]CustomerProfile{},
+	}
+}
+
+func (s *MemoryCustomerStore) Load(id string) (*CustomerProfile, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	p, ok := s.profiles[id]
+	if !ok {
+		return nil, nil
+	}
+	p.CardTokens = append([]string(nil), p.CardTokens...)
+	return &p, nil
+}
+
+func (s *MemoryCustomerStore) Store(p CustomerProfile) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	p.CardTokens = append([]string(nil), p.CardTokens...)
+	s.profiles[p.ID] = p
+	return nil
+}
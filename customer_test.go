@@ -0,0 +1,95 @@
+package vivawallet
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCustomerProfileCardTokens(t *testing.T) {
+	p := CustomerProfile{ID: "customer-1"}
+	p.AddCardToken("token-1")
+	p.AddCardToken("token-2")
+	p.AddCardToken("token-1")
+	if want := []string{"token-1", "token-2"}; !reflect.DeepEqual(p.CardTokens, want) {
+		t.Errorf("tokens after adding = %v, want %v", p.CardTokens, want)
+	}
+
+	p.RemoveCardToken("token-3")
+	p.RemoveCardToken("token-1")
+	if want := []string{"token-2"}; !reflect.DeepEqual(p.CardTokens, want) {
+		t.Errorf("tokens after removing = %v, want %v", p.CardTokens, want)
+	}
+
+	p.RemoveCardToken("token-2")
+	if len(p.CardTokens) != 0 {
+		t.Errorf("tokens after removing all = %v, want none", p.CardTokens)
+	}
+}
+
+func TestCustomerProfileRemoveCardTokenCopies(t *testing.T) {
+	tokens := []string{"token-1", "token-2", "token-3"}
+	p := CustomerProfile{ID: "customer-1", CardTokens: tokens}
+
+	p.RemoveCardToken("token-1")
+	if want := []string{"token-1", "token-2", "token-3"}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("caller's tokens = %v, want them untouched", tokens)
+	}
+	if want := []string{"token-2", "token-3"}; !reflect.DeepEqual(p.CardTokens, want) {
+		t.Errorf("profile tokens = %v, want %v", p.CardTokens, want)
+	}
+
+	order := p.Order(1000)
+	order.CardTokens[0] = "changed"
+	if p.CardTokens[0] != "token-2" {
+		t.Errorf("changing the order changed the profile tokens to %v", p.CardTokens)
+	}
+}
+
+func TestCustomerProfileJSON(t *testing.T) {
+	tests := map[string]CustomerProfile{
+		"full": {
+			ID:         "customer-1",
+			Customer:   Customer{Email: "user@example.com", FullName: "Jane Doe", Phone: "+306900000000", CountryCode: "GR", RequestLang: "el-GR"},
+			CardTokens: []string{"token-1", "token-2"},
+		},
+		"without tokens": {ID: "customer-2", Customer: Customer{Email: "user@example.com"}},
+	}
+	for name, p := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got CustomerProfile
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, p) {
+				t.Errorf("round trip of %s = %+v, want %+v", data, got, p)
+			}
+		})
+	}
+}
+
+func TestMemoryCustomerStore(t *testing.T) {
+	s := NewMemoryCustomerStore()
+	if p, err := s.Load("customer-1"); p != nil || err != nil {
+		t.Fatalf("Load() of a missing profile = %v, %v, want nil, nil", p, err)
+	}
+
+	tokens := []string{"token-1"}
+	if err := s.Store(CustomerProfile{ID: "customer-1", CardTokens: tokens}); err != nil {
+		t.Fatal(err)
+	}
+	tokens[0] = "changed"
+
+	p, err := s.Load("customer-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.CardTokens[0] = "changed again"
+	if p, _ := s.Load("customer-1"); p.CardTokens[0] != "token-1" {
+		t.Errorf("stored tokens = %v, want them copied in and out", p.CardTokens)
+	}
+}
@@ -0,0 +1,311 @@
+package vivawallet
+
+import "fmt"
+
+// TransactionType is the type of a transaction, as reported by the TransactionTypeID of
+// the transactions. Values unknown to the SDK are kept as is.
+type TransactionType int
+
+// Transaction types of Viva.
+const (
+	TransactionTypeCardCapture            TransactionType = 0
+	TransactionTypeCardPreAuth            TransactionType = 1
+	TransactionTypeCardRefund             TransactionType = 4
+	TransactionTypeCardCharge             TransactionType = 5
+	TransactionTypeCardChargeInstallments TransactionType = 6
+	TransactionTypeCardVoid               TransactionType = 7
+	TransactionTypeCardOriginalCredit     TransactionType = 8
+	TransactionTypeWalletCharge           TransactionType = 9
+	TransactionTypeWalletRefund           TransactionType = 10
+	TransactionTypeCardRefundClaimed      TransactionType = 13
+	TransactionTypeDias                   TransactionType = 15
+	TransactionTypeCash                   TransactionType = 16
+	TransactionTypeCashRefund             TransactionType = 17
+	TransactionTypeCardRefundInstallments TransactionType = 18
+	TransactionTypeCardPayout             TransactionType = 19
+	TransactionTypeAlipayCharge           TransactionType = 20
+	TransactionTypeAlipayRefund           TransactionType = 21
+	TransactionTypeCardManualDisbursement TransactionType = 22
+	TransactionTypeIDEALCharge            TransactionType = 23
+	TransactionTypeIDEALRefund            TransactionType = 24
+	TransactionTypeP24Charge              TransactionType = 25
+	TransactionTypeP24Refund              TransactionType = 26
+	TransactionTypeBLIKCharge             TransactionType = 27
+	TransactionTypeBLIKRefund             TransactionType = 28
+	TransactionTypePayUCharge             TransactionType = 29
+	TransactionTypePayURefund             TransactionType = 30
+	TransactionTypeCardWithdrawal         TransactionType = 31
+	TransactionTypeMultibancoCharge       TransactionType = 34
+	TransactionTypeMultibancoRefund       TransactionType = 35
+	TransactionTypeGiropayCharge          TransactionType = 36
+	TransactionTypeGiropayRefund          TransactionType = 37
+	TransactionTypeSofortCharge           TransactionType = 38
+	TransactionTypeSofortRefund           TransactionType = 39
+	TransactionTypeEPSCharge              TransactionType = 40
+	TransactionTypeEPSRefund              TransactionType = 41
+	TransactionTypeWeChatPayCharge        TransactionType = 42
+	TransactionTypeWeChatPayRefund        TransactionType = 43
+	TransactionTypePayPalCharge           TransactionType = 48
+	TransactionTypePayPalRefund           TransactionType = 49
+	TransactionTypeTrustlyCharge          TransactionType = 50
+	TransactionTypeTrustlyRefund          TransactionType = 51
+	TransactionTypeKlarnaCharge           TransactionType = 52
+	TransactionTypeKlarnaRefund           TransactionType = 53
+)
+
+// TransactionTypeRefund is the type of the refunds of card payments.
+const TransactionTypeRefund = TransactionTypeCardRefund
+
+var transactionTypeNames = map[TransactionType]string{
+	TransactionTypeCardCapture:            "CardCapture",
+	TransactionTypeCardPreAuth:            "CardPreAuth",
+	TransactionTypeCardRefund:             "CardRefund",
+	TransactionTypeCardCharge:             "CardCharge",
+	TransactionTypeCardChargeInstallments: "CardChargeInstallments",
+	TransactionTypeCardVoid:               "CardVoid",
+	TransactionTypeCardOriginalCredit:     "CardOriginalCredit",
+	TransactionTypeWalletCharge:           "WalletCharge",
+	TransactionTypeWalletRefund:           "WalletRefund",
+	TransactionTypeCardRefundClaimed:      "CardRefundClaimed",
+	TransactionTypeDias:                   "Dias",
+	TransactionTypeCash:                   "Cash",
+	TransactionTypeCashRefund:             "CashRefund",
+	TransactionTypeCardRefundInstallments: "CardRefundInstallments",
+	TransactionTypeCardPayout:             "CardPayout",
+	TransactionTypeAlipayCharge:           "AlipayCharge",
+	TransactionTypeAlipayRefund:           "AlipayRefund",
+	TransactionTypeCardManualDisbursement: "CardManualDisbursement",
+	TransactionTypeIDEALCharge:            "IDEALCharge",
+	TransactionTypeIDEALRefund:            "IDEALRefund",
+	TransactionTypeP24Charge:              "P24Charge",
+	TransactionTypeP24Refund:              "P24Refund",
+	TransactionTypeBLIKCharge:             "BLIKCharge",
+	TransactionTypeBLIKRefund:             "BLIKRefund",
+	TransactionTypePayUCharge:             "PayUCharge",
+	TransactionTypePayURefund:             "PayURefund",
+	TransactionTypeCardWithdrawal:         "CardWithdrawal",
+	TransactionTypeMultibancoCharge:       "MultibancoCharge",
+	TransactionTypeMultibancoRefund:       "MultibancoRefund",
+	TransactionTypeGiropayCharge:          "GiropayCharge",
+	TransactionTypeGiropayRefund:          "GiropayRefund",
+	TransactionTypeSofortCharge:           "SofortCharge",
+	TransactionTypeSofortRefund:           "SofortRefund",
+	TransactionTypeEPSCharge:              "EPSCharge",
+	TransactionTypeEPSRefund:              "EPSRefund",
+	TransactionTypeWeChatPayCharge:        "WeChatPayCharge",
+	TransactionTypeWeChatPayRefund:        "WeChatPayRefund",
+	TransactionTypePayPalCharge:           "PayPalCharge",
+	TransactionTypePayPalRefund:           "PayPalRefund",
+	TransactionTypeTrustlyCharge:          "TrustlyCharge",
+	TransactionTypeTrustlyRefund:          "TrustlyRefund",
+	TransactionTypeKlarnaCharge:           "KlarnaCharge",
+	TransactionTypeKlarnaRefund:           "KlarnaRefund",
+}
+
+var refundTypes = map[TransactionType]bool{
+	TransactionTypeCardRefund:             true,
+	TransactionTypeWalletRefund:           true,
+	TransactionTypeCardRefundClaimed:      true,
+	TransactionTypeCashRefund:             true,
+	TransactionTypeCardRefundInstallments: true,
+	TransactionTypeAlipayRefund:           true,
+	TransactionTypeIDEALRefund:            true,
+	TransactionTypeP24Refund:              true,
+	TransactionTypeBLIKRefund:             true,
+	TransactionTypePayURefund:             true,
+	TransactionTypeMultibancoRefund:       true,
+	TransactionTypeGiropayRefund:          true,
+	TransactionTypeSofortRefund:           true,
+	TransactionTypeEPSRefund:              true,
+	TransactionTypeWeChatPayRefund:        true,
+	TransactionTypePayPalRefund:           true,
+	TransactionTypeTrustlyRefund:          true,
+	TransactionTypeKlarnaRefund:           true,
+}
+
+func (t TransactionType) String() string {
+	if name, ok := transactionTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("TransactionType(%d)", int(t))
+}
+
+// IsRefund returns true if the transaction gives money back to the customer.
+func (t TransactionType) IsRefund() bool {
+	return refundTypes[t]
+}
+
+// IsPreAuth returns true if the transaction only authorizes the amount, which is
+// charged once captured.
+func (t TransactionType) IsPreAuth() bool {
+	return t == TransactionTypeCardPreAuth
+}
+
+// TransactionType returns the type of the transaction.
+func (t GetTransactionResponse) TransactionType() TransactionType {
+	return TransactionType(t.TransactionTypeID)
+}
+
+// Type returns the type of the transaction.
+func (t Transaction) Type() TransactionType {
+	return TransactionType(t.TransactionType.TransactionTypeID)
+}
+
+// TransactionType returns the type of the transaction.
+func (t TransactionEventData) TransactionType() TransactionType {
+	return TransactionType(t.TransactionTypeID)
+}
+
+// TransactionType returns the type of the transaction.
+func (r ReportRow) TransactionType() TransactionType {
+	return TransactionType(r.TransactionTypeID)
+}
+
+// CardType is the scheme of a card, as reported by the CardTypeID of the transactions.
+// Values unknown to the SDK are kept as is.
+type CardType int
+
+// Card schemes accepted by Viva.
+const (
+	CardTypeVisa       CardType = 0
+	CardTypeMastercard CardType = 1
+	CardTypeDiners     CardType = 2
+	CardTypeAmex       CardType = 3
+	CardTypeMaestro    CardType = 6
+	CardTypeDiscover   CardType = 7
+	CardTypeJCB        CardType = 8
+	CardTypeUnionPay   CardType = 9
+)
+
+var cardTypeNames = map[CardType]string{
+	CardTypeVisa:       "Visa",
+	CardTypeMastercard: "Mastercard",
+	CardTypeDiners:     "Diners",
+	CardTypeAmex:       "Amex",
+	CardTypeMaestro:    "Maestro",
+	CardTypeDiscover:   "Discover",
+	CardTypeJCB:        "JCB",
+	CardTypeUnionPay:   "UnionPay",
+}
+
+func (t CardType) String() string {
+	if name, ok := cardTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("CardType(%d)", int(t))
+}
+
+// CardType returns the scheme of the card the transaction was paid with.
+func (t GetTransactionResponse) CardType() CardType {
+	return CardType(t.CardTypeID)
+}
+
+// CardType returns the scheme of the card the transaction was paid with.
+func (t Transaction) CardType() CardType {
+	return CardType(t.CreditCard.CardType.CardTypeID)
+}
+
+// CardType returns the scheme of the card the transaction was paid with.
+func (r ReportRow) CardType() CardType {
+	return CardType(r.CardTypeID)
+}
+
+// ErrorCode is the reason a transaction was declined. Viva reports it in the EventId of
+// the responses, as 10000 plus the ISO 8583 response code of the issuer. Values unknown
+// to the SDK are kept as is.
+type ErrorCode int
+
+// Decline reasons of the transactions.
+const (
+	ErrorNone                     ErrorCode = 0
+	ErrorReferToIssuer            ErrorCode = 10001
+	ErrorInvalidMerchant          ErrorCode = 10003
+	ErrorPickUpCard               ErrorCode = 10004
+	ErrorDoNotHonor               ErrorCode = 10005
+	ErrorGeneral                  ErrorCode = 10006
+	ErrorInvalidTransaction       ErrorCode = 10012
+	ErrorInvalidAmount            ErrorCode = 10013
+	ErrorInvalidCardNumber        ErrorCode = 10014
+	ErrorInvalidIssuer            ErrorCode = 10015
+	ErrorFormat                   ErrorCode = 10030
+	ErrorLostCard                 ErrorCode = 10041
+	ErrorStolenCard               ErrorCode = 10043
+	ErrorInsufficientFunds        ErrorCode = 10051
+	ErrorExpiredCard              ErrorCode = 10054
+	ErrorIncorrectPIN             ErrorCode = 10055
+	ErrorNotPermittedToCardholder ErrorCode = 10057
+	ErrorNotPermittedToTerminal   ErrorCode = 10058
+	ErrorSuspectedFraud           ErrorCode = 10059
+	ErrorExceedsWithdrawalLimit   ErrorCode = 10061
+	ErrorRestrictedCard           ErrorCode = 10062
+	ErrorSecurityViolation        ErrorCode = 10063
+	ErrorExceedsFrequencyLimit    ErrorCode = 10065
+	ErrorPINTriesExceeded         ErrorCode = 10075
+	ErrorNegativeCVV              ErrorCode = 10082
+	ErrorIssuerUnavailable        ErrorCode = 10091
+	ErrorDuplicateTransaction     ErrorCode = 10094
+	ErrorSystemMalfunction        ErrorCode = 10096
+	ErrorSCARequired              ErrorCode = 10301
+)
+
+var errorCodeNames = map[ErrorCode]string{
+	ErrorNone:                     "None",
+	ErrorReferToIssuer:            "ReferToIssuer",
+	ErrorInvalidMerchant:          "InvalidMerchant",
+	ErrorPickUpCard:               "PickUpCard",
+	ErrorDoNotHonor:               "DoNotHonor",
+	ErrorGeneral:                  "General",
+	ErrorInvalidTransaction:       "InvalidTransaction",
+	ErrorInvalidAmount:            "InvalidAmount",
+	ErrorInvalidCardNumber:        "InvalidCardNumber",
+	ErrorInvalidIssuer:            "InvalidIssuer",
+	ErrorFormat:                   "Format",
+	ErrorLostCard:                 "LostCard",
+	ErrorStolenCard:               "StolenCard",
+	ErrorInsufficientFunds:        "InsufficientFunds",
+	ErrorExpiredCard:              "ExpiredCard",
+	ErrorIncorrectPIN:             "IncorrectPIN",
+	ErrorNotPermittedToCardholder: "NotPermittedToCardholder",
+	ErrorNotPermittedToTerminal:   "NotPermittedToTerminal",
+	ErrorSuspectedFraud:           "SuspectedFraud",
+	ErrorExceedsWithdrawalLimit:   "ExceedsWithdrawalLimit",
+	ErrorRestrictedCard:           "RestrictedCard",
+	ErrorSecurityViolation:        "SecurityViolation",
+	ErrorExceedsFrequencyLimit:    "ExceedsFrequencyLimit",
+	ErrorPINTriesExceeded:         "PINTriesExceeded",
+	ErrorNegativeCVV:              "NegativeCVV",
+	ErrorIssuerUnavailable:        "IssuerUnavailable",
+	ErrorDuplicateTransaction:     "DuplicateTransaction",
+	ErrorSystemMalfunction:        "SystemMalfunction",
+	ErrorSCARequired:              "SCARequired",
+}
+
+// retryableErrors are the soft declines, which may succeed when the payment is retried
+// later.
+var retryableErrors = map[ErrorCode]bool{
+	ErrorInsufficientFunds:      true,
+	ErrorExceedsWithdrawalLimit: true,
+	ErrorExceedsFrequencyLimit:  true,
+	ErrorIssuerUnavailable:      true,
+	ErrorSystemMalfunction:      true,
+}
+
+func (c ErrorCode) String() string {
+	if name, ok := errorCodeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("ErrorCode(%d)", int(c))
+}
+
+// IsRetryable returns true if the payment may succeed when retried later, e.g. once the
+// customer has enough funds. Hard declines such as a stolen card must not be retried.
+func (c ErrorCode) IsRetryable() bool {
+	return retryableErrors[c]
+}
+
+// Code returns the reason the transaction was declined, read from its EventId, or
+// ErrorNone if it was not. The ErrorCode of the response is not a decline but an error
+// of the request, e.g. an invalid amount, and is left to the caller.
+func (r TransactionResponse) Code() ErrorCode {
+	return ErrorCode(r.EventID)
+}
@@ -0,0 +1,106 @@
+package vivawallet
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEnumNames(t *testing.T) {
+	seen := map[string]bool{}
+	for v, name := range transactionTypeNames {
+		if v.String() != name || seen[name] {
+			t.Errorf("TransactionType(%d) = %s, want the unique name %s", int(v), v, name)
+		}
+		seen[name] = true
+	}
+
+	seen = map[string]bool{}
+	for v, name := range cardTypeNames {
+		if v.String() != name || seen[name] {
+			t.Errorf("CardType(%d) = %s, want the unique name %s", int(v), v, name)
+		}
+		seen[name] = true
+	}
+
+	seen = map[string]bool{}
+	for v, name := range errorCodeNames {
+		if v.String() != name || seen[name] {
+			t.Errorf("ErrorCode(%d) = %s, want the unique name %s", int(v), v, name)
+		}
+		seen[name] = true
+	}
+
+	unknown := map[string]string{
+		TransactionType(99).String(): "TransactionType(99)",
+		CardType(99).String():        "CardType(99)",
+		ErrorCode(10099).String():    "ErrorCode(10099)",
+		OrderState(9).String():       "OrderState(9)",
+	}
+	for got, want := range unknown {
+		if got != want {
+			t.Errorf("unknown value printed as %s, want %s", got, want)
+		}
+	}
+}
+
+func TestEnumsFromResponses(t *testing.T) {
+	var trx GetTransactionResponse
+	if err := json.Unmarshal([]byte(`{"transactionTypeId":4,"cardTypeId":1}`), &trx); err != nil {
+		t.Fatal(err)
+	}
+	if trx.TransactionType() != TransactionTypeCardRefund || trx.TransactionType().String() != "CardRefund" || !trx.TransactionType().IsRefund() {
+		t.Errorf("GetTransactionResponse type = %s, want a CardRefund", trx.TransactionType())
+	}
+	if trx.CardType() != CardTypeMastercard {
+		t.Errorf("GetTransactionResponse card = %s, want Mastercard", trx.CardType())
+	}
+
+	var listed Transaction
+	if err := json.Unmarshal([]byte(`{"TransactionType":{"TransactionTypeId":1},"CreditCard":{"CardType":{"CardTypeId":3}}}`), &listed); err != nil {
+		t.Fatal(err)
+	}
+	if listed.Type() != TransactionTypeCardPreAuth || !listed.Type().IsPreAuth() || listed.CardType() != CardTypeAmex {
+		t.Errorf("Transaction = %s %s, want a CardPreAuth by Amex", listed.Type(), listed.CardType())
+	}
+
+	var order GetOrderPaymentResponse
+	if err := json.Unmarshal([]byte(`{"StateId":3}`), &order); err != nil {
+		t.Fatal(err)
+	}
+	if order.State() != OrderPaid || order.State().String() != "Paid" {
+		t.Errorf("order state = %s, want Paid", order.State())
+	}
+
+	rows, err := ParseReport(strings.NewReader("transactionTypeId,cardTypeId\n52,9\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[0].TransactionType() != TransactionTypeKlarnaCharge || rows[0].CardType() != CardTypeUnionPay {
+		t.Errorf("report row = %s %s, want a KlarnaCharge by UnionPay", rows[0].TransactionType(), rows[0].CardType())
+	}
+}
+
+func TestTransactionResponseCode(t *testing.T) {
+	tests := []struct {
+		body      string
+		code      ErrorCode
+		retryable bool
+	}{
+		{`{"Success":true}`, ErrorNone, false},
+		{`{"EventId":10051,"ErrorCode":0}`, ErrorInsufficientFunds, true},
+		{`{"EventId":10043}`, ErrorStolenCard, false},
+		// The ErrorCode of a failed request is not a decline.
+		{`{"ErrorCode":403,"ErrorText":"Forbidden"}`, ErrorNone, false},
+		{`{"EventId":10005,"ErrorCode":10005}`, ErrorDoNotHonor, false},
+	}
+	for _, tt := range tests {
+		var r TransactionResponse
+		if err := json.Unmarshal([]byte(tt.body), &r); err != nil {
+			t.Fatal(err)
+		}
+		if r.Code() != tt.code || r.Code().IsRetryable() != tt.retryable {
+			t.Errorf("Code() of %s = %s, retryable %t, want %s, %t", tt.body, r.Code(), r.Code().IsRetryable(), tt.code, tt.retryable)
+		}
+	}
+}
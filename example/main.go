@@ -1,99 +1,113 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 
 	vivawallet "github.com/techpals-eu/viva-wallet-go"
 )
 
 func main() {
-	clientID := os.Getenv("VIVA_CLIENT_ID")
-	clientSecret := os.Getenv("VIVA_CLIENT_SECRET")
-	merchantID := os.Getenv("VIVA_MERCHANT_ID")
-	apiKey := os.Getenv("VIVA_API_KEY")
+	config, err := vivawallet.LoadConfig(vivawallet.LoadOptions{
+		Require: vivawallet.AuthOAuth | vivawallet.AuthBasic,
+	})
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		return
+	}
 
-	oauthClient := vivawallet.NewOAuth(clientID, clientSecret, true)
-	basicAuthClient := vivawallet.NewBasicAuth(merchantID, apiKey, true)
+	oauthClient := vivawallet.NewOAuthFromConfig(config)
+	basicAuthClient := vivawallet.NewBasicAuthFromConfig(config)
+	if err := run(context.Background(), os.Stdout, oauthClient, basicAuthClient); err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+	}
+}
+
+// run goes through the example flow, printing the results to w. It stops at the first
+// error the rest of the flow depends on.
+func run(ctx context.Context, w io.Writer, oauthClient *vivawallet.OAuthClient, basicAuthClient *vivawallet.BasicAuthClient) error {
+	api := vivawallet.NewAPI(oauthClient, basicAuthClient)
 
 	token, err := oauthClient.Authenticate()
 	if err != nil {
-		fmt.Printf("Error: %s\n", err.Error())
-		return
+		return err
 	}
-	fmt.Printf("Token: %s\n\n", token.AccessToken)
+	fmt.Fprintf(w, "Token: %s\n\n", token.AccessToken)
 
-	fmt.Printf("\nCreate order\n")
+	fmt.Fprintf(w, "\nCreate order\n")
 	req := vivawallet.CheckoutOrder{
-		Amount: 1000,
+		Amount:  1000,
 		PreAuth: true,
 	}
-	op, err2 := oauthClient.CreateOrderPayment(req)
+	op, err2 := api.Orders.Create(ctx, req)
 	if err2 != nil {
-		fmt.Printf("\nerr: %s\n", err2.Error())
-	} else {
-		fmt.Printf("\nOrderPayment: %d\n", op.OrderCode)
+		return err2
 	}
+	fmt.Fprintf(w, "\nOrderPayment: %d\n", op.OrderCode)
 
-	fmt.Printf("\nGet wallets\n")
-	wallets, err3 := basicAuthClient.GetWallets()
+	fmt.Fprintf(w, "\nGet wallets\n")
+	wallets, err3 := api.Wallets.List(ctx)
 	if err3 != nil {
-		fmt.Printf("\nerr: %s\n", err3.Error())
+		fmt.Fprintf(w, "\nerr: %s\n", err3.Error())
 	} else {
-		for _, w := range wallets {
-			fmt.Printf("\nWallet: %v\n", w)
+		for _, wallet := range wallets {
+			fmt.Fprintf(w, "\nWallet: %v\n", wallet)
 		}
 	}
 
-	fmt.Printf("\nGet transaction\n")
+	fmt.Fprintf(w, "\nGet transaction\n")
 	trxID := "a9531058-f0f7-44ff-a718-98920804ceab"
-	trx, err4 := oauthClient.GetTransaction(trxID)
+	trx, err4 := api.Transactions.Get(ctx, trxID)
 	if err4 != nil {
-		fmt.Printf("\nerr: %s\n", err4.Error())
+		fmt.Fprintf(w, "\nerr: %s\n", err4.Error())
 	} else {
-		fmt.Printf("\nTrx: %v\n", trx)
+		fmt.Fprintf(w, "\nTrx: %v\n", trx)
 	}
 
-	fmt.Printf("\nUpdate orderpayment\n")
+	fmt.Fprintf(w, "\nUpdate orderpayment\n")
 	update := vivawallet.UpdateOrderPayment{
 		Amount: 1200,
 	}
-	err6 := basicAuthClient.UpdateOrderPayment(op.OrderCode, update)
+	err6 := api.Orders.Update(ctx, op.OrderCode, update)
 	if err6 != nil {
-		fmt.Printf("\nerr: %s\n", err6.Error())
+		fmt.Fprintf(w, "\nerr: %s\n", err6.Error())
 	} else {
-		fmt.Println("\nsuccess")
+		fmt.Fprintln(w, "\nsuccess")
 	}
 
-	fmt.Printf("\nGet orderpayment\n")
-	opGet, err7 := basicAuthClient.GetOrderPayment(op.OrderCode)
+	fmt.Fprintf(w, "\nGet orderpayment\n")
+	opGet, err7 := api.Orders.Get(ctx, op.OrderCode)
 	if err7 != nil {
-		fmt.Printf("\nerr: %s\n", err7.Error())
+		fmt.Fprintf(w, "\nerr: %s\n", err7.Error())
 	} else {
-		fmt.Printf("%v", opGet)
-		fmt.Println("\nsuccess")
+		fmt.Fprintf(w, "%v", opGet)
+		fmt.Fprintln(w, "\nsuccess")
 	}
 
-	fmt.Printf("\nCancel orderpayment\n")
-	opCancel, err8 := basicAuthClient.CancelOrderPayment(op.OrderCode)
+	fmt.Fprintf(w, "\nCancel orderpayment\n")
+	opCancel, err8 := api.Orders.Cancel(ctx, op.OrderCode)
 	if err8 != nil {
-		fmt.Printf("\nerr: %s\n", err8.Error())
+		fmt.Fprintf(w, "\nerr: %s\n", err8.Error())
 	} else {
-		fmt.Printf("%v", opCancel)
-		fmt.Println("\nsuccess")
+		fmt.Fprintf(w, "%v", opCancel)
+		fmt.Fprintln(w, "\nsuccess")
 	}
 
-	trx2, err9 := basicAuthClient.CancelTransaction("aacf07cf-9102-4b02-8172-72b7e1efd5d9", 100, "Default")
+	cancel := vivawallet.CancelOptions{Amount: 100, SourceCode: "Default"}
+	trx2, err9 := api.Transactions.Cancel(ctx, "aacf07cf-9102-4b02-8172-72b7e1efd5d9", cancel)
 	if err9 != nil {
-		fmt.Printf("\nerr: %s\n", err9.Error())
+		fmt.Fprintf(w, "\nerr: %s\n", err9.Error())
 	} else {
-		fmt.Printf("%v", trx2)
-		fmt.Println("\nsuccess")
+		fmt.Fprintf(w, "%v", trx2)
+		fmt.Fprintln(w, "\nsuccess")
 	}
 
 	payload := vivawallet.CreateTransaction{
 		Amount: 100,
 	}
-	trx3, err2 := basicAuthClient.CreateTransaction("cdc8e764-daf3-49de-9f44-c7f3b563c2d6", payload)
-	fmt.Printf("%v\nERR: %v\n", trx3, err2)
+	trx3, err10 := api.Transactions.Create(ctx, "cdc8e764-daf3-49de-9f44-c7f3b563c2d6", payload)
+	fmt.Fprintf(w, "%v\nERR: %v\n", trx3, err10)
+	return nil
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+	"github.com/techpals-eu/viva-wallet-go/vivatest"
+)
+
+// TestRun replays the example flow from its cassette. Set VIVA_RECORD=1 along with the
+// VIVA_ credentials of a demo account to record it again.
+func TestRun(t *testing.T) {
+	rec := vivatest.Start(t, "testdata/example.json")
+
+	config := vivawallet.Config{
+		Demo:         true,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		MerchantID:   "merchant-id",
+		APIKey:       "api-key",
+	}
+	if rec.Recording() {
+		var err error
+		config, err = vivawallet.LoadConfig(vivawallet.LoadOptions{
+			Require: vivawallet.AuthOAuth | vivawallet.AuthBasic,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oauthClient := vivawallet.NewOAuthFromConfig(config, vivawallet.WithTransport(rec))
+	basicAuthClient := vivawallet.NewBasicAuthFromConfig(config, vivawallet.WithTransport(rec))
+
+	var out bytes.Buffer
+	if err := run(context.Background(), &out, oauthClient, basicAuthClient); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "err:") || !strings.Contains(out.String(), "ERR: <nil>") {
+		t.Errorf("example flow failed:\n%s", out.String())
+	}
+}
@@ -0,0 +1,131 @@
+package vivawallet
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// hooks are the optional observers of the requests a client performs.
+type hooks struct {
+	logger     *slog.Logger
+	redaction  []RedactionRule
+	tracer     Tracer
+	metrics    Metrics
+	limiter    *RateLimiter
+	credential string
+	attrs      []Attribute
+}
+
+func (c BasicAuthClient) hooks() hooks {
+	return hooks{
+		logger:     c.Logger,
+		redaction:  c.Redaction,
+		tracer:     c.Tracer,
+		metrics:    c.Metrics,
+		limiter:    c.Limiter,
+		credential: c.Config.MerchantID,
+		attrs:      []Attribute{{Key: AttrMerchantID, Value: c.Config.MerchantID}},
+	}
+}
+
+func (c OAuthClient) hooks() hooks {
+	return hooks{
+		logger:     c.Logger,
+		redaction:  c.Redaction,
+		tracer:     c.Tracer,
+		metrics:    c.Metrics,
+		limiter:    c.Limiter,
+		credential: c.Config.ClientID,
+		attrs:      []Attribute{{Key: AttrClientID, Value: c.Config.ClientID}},
+	}
+}
+
+// send performs the request and reports it to the hooks. The body of the response is
+// returned whatever its status, it is up to the caller to check it.
+func send(client *http.Client, h hooks, req *http.Request) (*http.Response, []byte, error) {
+	req, span := h.startSpan(req)
+	defer span.End()
+
+	for attempt := 0; ; attempt++ {
+		resp, body, err := h.perform(client, req, span)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= h.limiter.retries() {
+			return resp, body, err
+		}
+
+		retry, rewindErr := rewind(req)
+		if rewindErr != nil {
+			return resp, body, nil
+		}
+		req = retry
+		h.observeRetry(req)
+	}
+}
+
+func (h hooks) perform(client *http.Client, req *http.Request, span Span) (*http.Response, []byte, error) {
+	if err := h.limiter.wait(req.Context(), h.credential, h.metrics); err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	start := time.Now()
+	resp, httpErr := client.Do(req)
+	if httpErr != nil {
+		h.observe(req, 0, time.Since(start))
+		h.logFailure(req, time.Since(start), httpErr)
+		span.RecordError(httpErr)
+		return nil, nil, httpErr
+	}
+
+	defer resp.Body.Close()
+	body, bodyErr := io.ReadAll(resp.Body)
+	if bodyErr != nil {
+		h.observe(req, resp.StatusCode, time.Since(start))
+		h.logFailure(req, time.Since(start), bodyErr)
+		span.RecordError(bodyErr)
+		return nil, nil, bodyErr
+	}
+
+	h.limiter.update(h.credential, resp, h.metrics)
+	h.observe(req, resp.StatusCode, time.Since(start))
+	h.logResponse(req, resp, body, time.Since(start))
+	span.SetAttributes(responseAttributes(resp.StatusCode, body)...)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		span.RecordError(fmt.Errorf("status %d", resp.StatusCode))
+	}
+	return resp, body, nil
+}
+
+// rewind returns a copy of the request that can be sent again.
+func rewind(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("request body cannot be sent again")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retry.Body = body
+	return retry, nil
+}
+
+func (h hooks) observe(req *http.Request, status int, latency time.Duration) {
+	if h.metrics == nil {
+		return
+	}
+	h.metrics.ObserveRequest(operationFrom(req.Context()).name, status, latency)
+}
+
+func (h hooks) observeRetry(req *http.Request) {
+	if h.metrics == nil {
+		return
+	}
+	h.metrics.ObserveRetry(operationFrom(req.Context()).name)
+}
@@ -0,0 +1,222 @@
+package vivawallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IdempotencyHeader is the header carrying the idempotency key of a request.
+const IdempotencyHeader = "Idempotency-Key"
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey sets the idempotency key of the create calls made with ctx. The
+// key is sent to Viva, and when the client has an IdempotencyStore the response is
+// journaled so that repeating the call with the same key returns the original response
+// instead of creating the resource again.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+func idempotencyKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	return key
+}
+
+// idempotencyHeaderKey marks the context of the create call sending the idempotency key,
+// so that the other requests made with the context, e.g. for a token, do not send it.
+type idempotencyHeaderKey struct{}
+
+// Idempotency record states.
+const (
+	IdempotencyPending = "pending"
+	IdempotencyDone    = "done"
+)
+
+// IdempotencyRecord is the journal entry of a call made with an idempotency key. A
+// pending record is a call whose outcome is unknown, e.g. because it timed out.
+type IdempotencyRecord struct {
+	State    string          `json:"state"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Created  time.Time       `json:"created"`
+}
+
+// IdempotencyStore journals the calls made with an idempotency key. Load returns a nil
+// record and no error when there is no record for the key.
+type IdempotencyStore interface {
+	Load(key string) (*IdempotencyRecord, error)
+	Store(key string, r IdempotencyRecord) error
+}
+
+// DefaultIdempotencyTTL is how long the in-memory journal keeps its records by default.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// MemoryIdempotencyStore keeps the journal in memory. Records older than TTL are
+// forgotten, so that a long running process does not accumulate them. A zero TTL keeps
+// them forever.
+type MemoryIdempotencyStore struct {
+	TTL time.Duration
+
+	lock    sync.RWMutex
+	records map[string]IdempotencyRecord
+	swept   time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory journal keeping its records for
+// DefaultIdempotencyTTL.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		TTL:     DefaultIdempotencyTTL,
+		records: map[string]IdempotencyRecord{},
+	}
+}
+
+func (s *MemoryIdempotencyStore) Load(key string) (*IdempotencyRecord, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	r, ok := s.records[key]
+	if !ok || s.expired(r, time.Now()) {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+func (s *MemoryIdempotencyStore) Store(key string, r IdempotencyRecord) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.records == nil {
+		s.records = map[string]IdempotencyRecord{}
+	}
+	s.records[key] = r
+	s.sweep(time.Now())
+	return nil
+}
+
+// sweep removes the expired records, at most once per minute.
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if s.TTL <= 0 || now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+
+	for key, r := range s.records {
+		if s.expired(r, now) {
+			delete(s.records, key)
+		}
+	}
+}
+
+func (s *MemoryIdempotencyStore) expired(r IdempotencyRecord, now time.Time) bool {
+	return s.TTL > 0 && now.Sub(r.Created) > s.TTL
+}
+
+// keyLocks serializes the calls made with the same idempotency key within the process.
+type keyLocks struct {
+	lock  sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+var idempotencyLocks = &keyLocks{locks: map[string]*keyLock{}}
+
+func (l *keyLocks) acquire(key string) func() {
+	l.lock.Lock()
+	k, ok := l.locks[key]
+	if !ok {
+		k = &keyLock{}
+		l.locks[key] = k
+	}
+	k.refs++
+	l.lock.Unlock()
+
+	k.Lock()
+	return func() {
+		k.Unlock()
+
+		l.lock.Lock()
+		k.refs--
+		if k.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.lock.Unlock()
+	}
+}
+
+// idempotent performs call at most once per idempotency key of ctx. Keys are scoped to
+// the credential of the client, i.e. its merchant or client id, so that clients sharing
+// a store never get each other's responses. When a previous call with the key is
+// pending, lookup, if given, looks for its outcome before calling again. Without a key
+// or a store, call is simply performed. The context given to call sends the key to Viva.
+//
+// Failing to journal a successful call does not fail it, the record is left pending
+// and the next call with the key looks the outcome up again.
+func idempotent[T any](ctx context.Context, store IdempotencyStore, credential string, op string, call func(ctx context.Context) (*T, error), lookup func(created time.Time) (*T, error)) (*T, error) {
+	key := idempotencyKeyFrom(ctx)
+	if key == "" {
+		return call(ctx)
+	}
+	callCtx := context.WithValue(ctx, idempotencyHeaderKey{}, key)
+	if store == nil {
+		return call(callCtx)
+	}
+
+	key = op + ":" + credential + ":" + key
+	release := idempotencyLocks.acquire(key)
+	defer release()
+
+	record, err := store.Load(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load idempotency record %s", err)
+	}
+
+	if record != nil && record.State == IdempotencyDone {
+		r := new(T)
+		if err := json.Unmarshal(record.Response, r); err != nil {
+			return nil, fmt.Errorf("failed to parse idempotency record %s", err)
+		}
+		return r, nil
+	}
+
+	if record != nil && record.State == IdempotencyPending && lookup != nil {
+		r, err := lookup(record.Created)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			storeDone(store, key, record.Created, r)
+			return r, nil
+		}
+	}
+
+	created := time.Now()
+	if record != nil {
+		created = record.Created
+	}
+	if err := store.Store(key, IdempotencyRecord{State: IdempotencyPending, Created: created}); err != nil {
+		return nil, fmt.Errorf("failed to store idempotency record %s", err)
+	}
+
+	r, err := call(callCtx)
+	if err != nil {
+		return nil, err
+	}
+	storeDone(store, key, created, r)
+	return r, nil
+}
+
+func storeDone(store IdempotencyStore, key string, created time.Time, response interface{}) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	_ = store.Store(key, IdempotencyRecord{State: IdempotencyDone, Response: data, Created: created})
+}
@@ -0,0 +1,221 @@
+package vivawallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdempotentReplaysDoneRecord(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	ctx := WithIdempotencyKey(context.Background(), "key")
+	var calls int32
+	call := func(ctx context.Context) (*CheckoutOrderResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		return &CheckoutOrderResponse{OrderCode: int64(atomic.LoadInt32(&calls))*100 + 1}, nil
+	}
+
+	first, err := idempotent(ctx, store, "client-id", "CreateOrderPayment", call, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := idempotent(ctx, store, "client-id", "CreateOrderPayment", call, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("called %d times, want 1", n)
+	}
+	if first.OrderCode != 101 || second.OrderCode != 101 {
+		t.Errorf("responses %d and %d, want the original 101 twice", first.OrderCode, second.OrderCode)
+	}
+
+	record, _ := store.Load("CreateOrderPayment:client-id:key")
+	if record == nil || record.State != IdempotencyDone {
+		t.Errorf("record %+v, want done", record)
+	}
+}
+
+func TestIdempotentWithoutKey(t *testing.T) {
+	var calls int32
+	call := func(ctx context.Context) (*CheckoutOrderResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		if ctx.Value(idempotencyHeaderKey{}) != nil {
+			t.Error("call without a key sends one")
+		}
+		return &CheckoutOrderResponse{}, nil
+	}
+
+	store := NewMemoryIdempotencyStore()
+	for i := 0; i < 2; i++ {
+		if _, err := idempotent(context.Background(), store, "client-id", "CreateOrderPayment", call, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("called %d times, want 2", n)
+	}
+}
+
+// transactionServer serves the transaction search with trxs and counts the creations.
+func transactionServer(t *testing.T, created *int32, trxs []Transaction) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/transactions":
+			_ = json.NewEncoder(w).Encode(ListTransactionsResponse{Transactions: trxs})
+		case r.Method == "POST" && r.URL.Path == "/api/transactions/initial":
+			n := atomic.AddInt32(created, 1)
+			if r.Header.Get(IdempotencyHeader) != "key" {
+				t.Errorf("create sent idempotency key %q, want key", r.Header.Get(IdempotencyHeader))
+			}
+			_ = json.NewEncoder(w).Encode(TransactionResponse{TransactionID: fmt.Sprintf("created-%d", n), Success: true})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCreateTransactionRecoversPendingRecord(t *testing.T) {
+	started := time.Now().Add(-10 * time.Minute)
+	found := func(id string, parentID string, merchantTrns string, amount float64, insDate time.Time) Transaction {
+		return Transaction{TransactionID: id, ParentID: parentID, MerchantTrns: merchantTrns, Amount: amount, StatusID: StatusFinished, InsDate: insDate}
+	}
+
+	var created int32
+	srv := transactionServer(t, &created, []Transaction{
+		found("other-parent", "other", "sub-42", 10, started.Add(time.Minute)),
+		found("other-reference", "initial", "sub-43", 10, started.Add(time.Minute)),
+		found("other-amount", "initial", "sub-42", 12, started.Add(time.Minute)),
+		found("before", "initial", "sub-42", 10, started.Add(-2*clockSkew)),
+		found("timed-out", "initial", "sub-42", 10, started.Add(time.Minute)),
+	})
+
+	store := NewMemoryIdempotencyStore()
+	key := "CreateTransaction:merchant-id:key"
+	_ = store.Store(key, IdempotencyRecord{State: IdempotencyPending, Created: started})
+
+	basic := NewBasicAuth("merchant-id", "api-key", true, WithBaseURL(srv.URL))
+	basic.Idempotency = store
+	api := NewAPI(nil, basic)
+
+	ctx := WithIdempotencyKey(context.Background(), "key")
+	trx, err := api.Transactions.Create(ctx, "initial", CreateTransaction{Amount: 1000, MerchantTrns: "sub-42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trx.TransactionID != "timed-out" {
+		t.Errorf("Create() returned %s, want the transaction of the pending call", trx.TransactionID)
+	}
+	if n := atomic.LoadInt32(&created); n != 0 {
+		t.Errorf("created %d transactions, want none", n)
+	}
+	if record, _ := store.Load(key); record == nil || record.State != IdempotencyDone {
+		t.Errorf("record %+v, want done", record)
+	}
+}
+
+func TestCreateTransactionRetriesPendingRecordNotFound(t *testing.T) {
+	var created int32
+	srv := transactionServer(t, &created, nil)
+
+	store := NewMemoryIdempotencyStore()
+	_ = store.Store("CreateTransaction:merchant-id:key", IdempotencyRecord{State: IdempotencyPending, Created: time.Now()})
+
+	basic := NewBasicAuth("merchant-id", "api-key", true, WithBaseURL(srv.URL))
+	basic.Idempotency = store
+	api := NewAPI(nil, basic)
+
+	ctx := WithIdempotencyKey(context.Background(), "key")
+	if _, err := api.Transactions.Create(ctx, "initial", CreateTransaction{Amount: 1000, MerchantTrns: "sub-42"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&created); n != 1 {
+		t.Errorf("created %d transactions, want 1", n)
+	}
+}
+
+func TestIdempotencyKeysPerCredential(t *testing.T) {
+	var created int32
+	srv := transactionServer(t, &created, nil)
+	store := NewMemoryIdempotencyStore()
+
+	ctx := WithIdempotencyKey(context.Background(), "key")
+	var ids []string
+	for _, merchantID := range []string{"merchant-a", "merchant-b", "merchant-a"} {
+		basic := NewBasicAuth(merchantID, "api-key", true, WithBaseURL(srv.URL))
+		basic.Idempotency = store
+
+		trx, err := NewAPI(nil, basic).Transactions.Create(ctx, "initial", CreateTransaction{Amount: 1000})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, trx.TransactionID)
+	}
+
+	if n := atomic.LoadInt32(&created); n != 2 {
+		t.Errorf("created %d transactions, want one per merchant", n)
+	}
+	if ids[0] == ids[1] || ids[0] != ids[2] {
+		t.Errorf("transactions %q, want merchant-a to get its own back", ids)
+	}
+}
+
+func TestIdempotencyKeyOnlySentByCreateCall(t *testing.T) {
+	var tokenKeys, orderKeys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/connect/token":
+			tokenKeys = append(tokenKeys, r.Header.Get(IdempotencyHeader))
+			_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+		case "/checkout/v2/orders":
+			orderKeys = append(orderKeys, r.Header.Get(IdempotencyHeader))
+			_, _ = w.Write([]byte(`{"orderCode":1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	api := NewAPI(NewOAuth("client-id", "client-secret", true, WithBaseURL(srv.URL)), nil)
+	ctx := WithIdempotencyKey(context.Background(), "key")
+	if _, err := api.Orders.Create(ctx, CheckoutOrder{Amount: 1000}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tokenKeys) != 1 || tokenKeys[0] != "" {
+		t.Errorf("token requests sent keys %q, want none", tokenKeys)
+	}
+	if len(orderKeys) != 1 || orderKeys[0] != "key" {
+		t.Errorf("order requests sent keys %q, want key", orderKeys)
+	}
+}
+
+func TestMemoryIdempotencyStoreExpires(t *testing.T) {
+	s := NewMemoryIdempotencyStore()
+	s.TTL = time.Hour
+
+	old := time.Now().Add(-2 * time.Hour)
+	_ = s.Store("old", IdempotencyRecord{State: IdempotencyDone, Created: old})
+	if r, _ := s.Load("old"); r != nil {
+		t.Errorf("Load() = %+v, want the expired record forgotten", r)
+	}
+
+	s.swept = time.Time{}
+	_ = s.Store("new", IdempotencyRecord{State: IdempotencyDone, Created: time.Now()})
+	if _, ok := s.records["old"]; ok {
+		t.Error("expired record was not removed")
+	}
+	if r, _ := s.Load("new"); r == nil {
+		t.Error("Load() forgot a recent record")
+	}
+}
@@ -0,0 +1,262 @@
+package vivawallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// RedactionRule hides the value of a header, a json field or a form field from the
+// logs. Names are matched case insensitively. When Redact is nil the whole value is
+// replaced.
+type RedactionRule struct {
+	Field  string
+	Redact func(value string) string
+}
+
+// KeepLast returns a redaction function that keeps the last n characters of a value,
+// e.g. the last 4 digits of a card number.
+func KeepLast(n int) func(string) string {
+	return func(v string) string {
+		if len(v) <= n {
+			return redacted
+		}
+		return strings.Repeat("*", len(v)-n) + v[len(v)-n:]
+	}
+}
+
+// DefaultRedactionRules returns the rules used when a client has no rules set. They
+// hide credentials, access tokens, card data and contact details of the customers.
+func DefaultRedactionRules() []RedactionRule {
+	return []RedactionRule{
+		{Field: "Authorization"},
+		{Field: "access_token"},
+		{Field: "accessToken"},
+		{Field: "client_secret"},
+		{Field: "cardNumber"},
+		{Field: "number"},
+		{Field: "cvc"},
+		{Field: "holderName"},
+		{Field: "token"},
+		{Field: "chargeToken"},
+		{Field: "cardTokens"},
+		{Field: "email"},
+		{Field: "phone"},
+	}
+}
+
+func (h hooks) logFailure(req *http.Request, latency time.Duration, err error) {
+	if h.logger == nil {
+		return
+	}
+
+	h.logger.LogAttrs(req.Context(), slog.LevelError, "viva request failed",
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.Redacted()),
+		slog.Duration("latency", latency),
+		slog.String("error", err.Error()),
+	)
+}
+
+func (h hooks) logResponse(req *http.Request, resp *http.Response, body []byte, latency time.Duration) {
+	if h.logger == nil {
+		return
+	}
+
+	level := slog.LevelInfo
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		level = slog.LevelWarn
+	}
+
+	ctx := req.Context()
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.Redacted()),
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("latency", latency),
+	}
+	if id := correlationID(resp.Header, body); id != "" {
+		attrs = append(attrs, slog.String("correlation_id", id))
+	}
+	h.logger.LogAttrs(ctx, level, "viva request", attrs...)
+
+	if !h.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	rules := h.rules()
+	h.logger.LogAttrs(ctx, slog.LevelDebug, "viva request details",
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.Redacted()),
+		slog.Any("request_headers", redactHeaders(req.Header, rules)),
+		slog.String("request_body", redactBody(requestBody(req), req.Header.Get("Content-Type"), rules)),
+		slog.Any("response_headers", redactHeaders(resp.Header, rules)),
+		slog.String("response_body", redactBody(body, resp.Header.Get("Content-Type"), rules)),
+	)
+}
+
+func (h hooks) rules() []RedactionRule {
+	if h.redaction == nil {
+		return DefaultRedactionRules()
+	}
+	return h.redaction
+}
+
+// correlationID looks for the correlation id Viva includes in most of its responses.
+func correlationID(header http.Header, body []byte) string {
+	if id := header.Get("X-Correlation-Id"); id != "" {
+		return id
+	}
+
+	var r struct {
+		CorrelationID string `json:"CorrelationId"`
+	}
+	if json.Unmarshal(body, &r) == nil {
+		return r.CorrelationID
+	}
+	return ""
+}
+
+func requestBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+
+	r, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+
+	data, _ := io.ReadAll(r)
+	return data
+}
+
+// findRule returns the last rule for the name, so that appended rules override the
+// default ones.
+func findRule(rules []RedactionRule, name string) (RedactionRule, bool) {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if strings.EqualFold(rules[i].Field, name) {
+			return rules[i], true
+		}
+	}
+	return RedactionRule{}, false
+}
+
+func (r RedactionRule) apply(v string) string {
+	if r.Redact == nil {
+		return redacted
+	}
+	return r.Redact(v)
+}
+
+// RedactHeader returns a copy of the header with the values of the fields matching the
+// rules redacted.
+func RedactHeader(header http.Header, rules []RedactionRule) http.Header {
+	r := header.Clone()
+	for name, values := range r {
+		if rule, ok := findRule(rules, name); ok {
+			for i := range values {
+				values[i] = rule.apply(values[i])
+			}
+		}
+	}
+	return r
+}
+
+// RedactBody redacts a json or a form encoded body. Other bodies are returned unchanged
+// along with false, since there is no telling what they contain.
+func RedactBody(body []byte, contentType string, rules []RedactionRule) ([]byte, bool) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body, true
+	}
+
+	// Numbers are kept as written, since order codes do not fit in a float64.
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&v); err == nil && !d.More() {
+		data, err := json.Marshal(redactValue(v, rules))
+		return data, err == nil
+	}
+
+	if !strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		return body, false
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return body, false
+	}
+	for name, values := range form {
+		if rule, ok := findRule(rules, name); ok {
+			for i := range values {
+				values[i] = rule.apply(values[i])
+			}
+		}
+	}
+	return []byte(form.Encode()), true
+}
+
+func redactHeaders(header http.Header, rules []RedactionRule) map[string]string {
+	r := map[string]string{}
+	for name, values := range RedactHeader(header, rules) {
+		r[name] = strings.Join(values, ", ")
+	}
+	return r
+}
+
+// redactBody redacts a body for the logs, dropping it altogether when it is neither json
+// nor form encoded.
+func redactBody(body []byte, contentType string, rules []RedactionRule) string {
+	data, ok := RedactBody(body, contentType, rules)
+	if !ok {
+		return redacted
+	}
+	return string(data)
+}
+
+func redactValue(v interface{}, rules []RedactionRule) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			rule, ok := findRule(rules, k)
+			if !ok {
+				v[k] = redactValue(field, rules)
+				continue
+			}
+			v[k] = redactField(field, rule)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i], rules)
+		}
+		return v
+	}
+	return v
+}
+
+// redactField redacts every value of a field, including the elements of lists such as
+// the card tokens.
+func redactField(v interface{}, rule RedactionRule) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return rule.apply(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = redactField(v[i], rule)
+		}
+		return v
+	}
+	return redacted
+}
@@ -0,0 +1,143 @@
+package vivawallet
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	rules := append(DefaultRedactionRules(), RedactionRule{Field: "cardNumber", Redact: KeepLast(4)})
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+		ok          bool
+	}{
+		{
+			name: "nested fields",
+			body: `{"customer":{"email":"user@example.com","fullName":"Jane"},"amount":1000}`,
+			want: `{"amount":1000,"customer":{"email":"[REDACTED]","fullName":"Jane"}}`,
+			ok:   true,
+		},
+		{
+			name: "lists of objects",
+			body: `[{"CardNumber":"4111111111111111"},{"cardNumber":"5555555555554444"}]`,
+			want: `[{"CardNumber":"************1111"},{"cardNumber":"************4444"}]`,
+			ok:   true,
+		},
+		{
+			name: "lists of values",
+			body: `{"cardTokens":["a","b"],"tags":["x"]}`,
+			want: `{"cardTokens":["[REDACTED]","[REDACTED]"],"tags":["x"]}`,
+			ok:   true,
+		},
+		{
+			name: "objects and numbers of redacted fields",
+			body: `{"token":{"value":"secret"},"cvc":123,"phone":null}`,
+			want: `{"cvc":"[REDACTED]","phone":null,"token":"[REDACTED]"}`,
+			ok:   true,
+		},
+		{
+			name: "large numbers",
+			body: `{"orderCode":9007199254740993}`,
+			want: `{"orderCode":9007199254740993}`,
+			ok:   true,
+		},
+		{
+			name:        "form",
+			body:        "grant_type=client_credentials&client_secret=secret",
+			contentType: "application/x-www-form-urlencoded",
+			want:        "client_secret=%5BREDACTED%5D&grant_type=client_credentials",
+			ok:          true,
+		},
+		{
+			name:        "other",
+			body:        "email\nuser@example.com",
+			contentType: "text/csv",
+			want:        "email\nuser@example.com",
+			ok:          false,
+		},
+		{
+			name: "trailing data",
+			body: `{"email":"user@example.com"} trailing`,
+			want: `{"email":"user@example.com"} trailing`,
+			ok:   false,
+		},
+		{
+			name: "empty",
+			ok:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RedactBody([]byte(tt.body), tt.contentType, rules)
+			if string(got) != tt.want || ok != tt.ok {
+				t.Errorf("RedactBody() = %s, %v, want %s, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	header := http.Header{"Authorization": {"Bearer token"}, "Content-Type": {"application/json"}}
+	got := RedactHeader(header, DefaultRedactionRules())
+
+	if got.Get("Authorization") != redacted || got.Get("Content-Type") != "application/json" {
+		t.Errorf("RedactHeader() = %v", got)
+	}
+	if header.Get("Authorization") != "Bearer token" {
+		t.Error("RedactHeader() changed the original header")
+	}
+}
+
+func TestFindRuleLastWins(t *testing.T) {
+	rules := append(DefaultRedactionRules(), RedactionRule{Field: "EMAIL", Redact: KeepLast(3)})
+	rule, ok := findRule(rules, "email")
+	if !ok || rule.apply("user@example.com") != "*************com" {
+		t.Errorf("findRule() did not return the appended rule")
+	}
+}
+
+func TestKeepLast(t *testing.T) {
+	tests := map[string]string{
+		"4111111111111111": "************1111",
+		"1234":             redacted,
+		"":                 redacted,
+	}
+	for in, want := range tests {
+		if got := KeepLast(4)(in); got != want {
+			t.Errorf("KeepLast(4)(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLogsAreRedacted(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"token":"charge-token"}`))
+	})
+
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	api.Transactions.oauth.Logger = logger
+
+	_, err := api.Transactions.CreateCardToken(context.Background(), CreateCardToken{TransactionID: "trx-id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logs := out.String()
+	for _, secret := range []string{"client-secret", "Bearer token", "charge-token"} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("logs contain %q:\n%s", secret, logs)
+		}
+	}
+	if !strings.Contains(logs, "trx-id") {
+		t.Errorf("logs miss the request body:\n%s", logs)
+	}
+}
@@ -0,0 +1,22 @@
+package vivawallet
+
+import (
+	"time"
+)
+
+// Metrics receives the measurements of the clients. Endpoints are named after the
+// operation, e.g. `CreateOrderPayment`, to keep their cardinality low. See the vivaprom
+// package for a Prometheus implementation.
+type Metrics interface {
+	// ObserveRequest is called once per request. The status is 0 when no response was
+	// received.
+	ObserveRequest(endpoint string, status int, latency time.Duration)
+	// ObserveTokenRefresh is called once per access token request, with its error if it
+	// failed.
+	ObserveTokenRefresh(err error)
+	// ObserveRetry is called every time a request is sent again.
+	ObserveRetry(endpoint string)
+	// ObserveWebhook is called once per webhook event received, with the error of its
+	// handler if it failed.
+	ObserveWebhook(eventTypeID int, err error)
+}
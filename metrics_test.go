@@ -0,0 +1,153 @@
+package vivawallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeMetrics records the measurements it receives.
+type fakeMetrics struct {
+	lock      sync.Mutex
+	requests  []string
+	refreshes []error
+	retries   []string
+	webhooks  []string
+}
+
+func (m *fakeMetrics) ObserveRequest(endpoint string, status int, latency time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.requests = append(m.requests, fmt.Sprintf("%s %d", endpoint, status))
+}
+
+func (m *fakeMetrics) ObserveTokenRefresh(err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.refreshes = append(m.refreshes, err)
+}
+
+func (m *fakeMetrics) ObserveRetry(endpoint string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.retries = append(m.retries, endpoint)
+}
+
+func (m *fakeMetrics) ObserveWebhook(eventTypeID int, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.webhooks = append(m.webhooks, fmt.Sprintf("%d %t", eventTypeID, err == nil))
+}
+
+func TestMetricsObserveRequests(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/wallets") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"statusId":"F"}`))
+	})
+	m := &fakeMetrics{}
+	api.Transactions.oauth.Metrics = m
+	api.Transactions.basic.Metrics = m
+
+	ctx := context.Background()
+	if _, err := api.Transactions.Get(ctx, "trx-id"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.Wallets.List(ctx); err == nil {
+		t.Fatal("List() succeeded, want the server error")
+	}
+
+	want := []string{"Authenticate 200", "GetTransaction 200", "GetWallets 500"}
+	if !reflect.DeepEqual(m.requests, want) {
+		t.Errorf("requests %q, want %q", m.requests, want)
+	}
+	if len(m.refreshes) != 1 || m.refreshes[0] != nil {
+		t.Errorf("token refreshes %v, want one successful", m.refreshes)
+	}
+}
+
+func TestMetricsObserveFailures(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	m := &fakeMetrics{}
+	oauth := NewOAuth("client-id", "client-secret", true, WithBaseURL(srv.URL))
+	oauth.Metrics = m
+	basic := NewBasicAuth("merchant-id", "api-key", true, WithBaseURL(srv.URL))
+	basic.Metrics = m
+	api := NewAPI(oauth, basic)
+
+	if _, err := api.Wallets.List(context.Background()); err == nil {
+		t.Fatal("List() succeeded, want the connection error")
+	}
+	if _, err := oauth.Authenticate(); err == nil {
+		t.Fatal("Authenticate() succeeded, want the connection error")
+	}
+
+	want := []string{"GetWallets 0", "Authenticate 0"}
+	if !reflect.DeepEqual(m.requests, want) {
+		t.Errorf("requests %q, want %q", m.requests, want)
+	}
+	if len(m.refreshes) != 1 || m.refreshes[0] == nil {
+		t.Errorf("token refreshes %v, want one failed", m.refreshes)
+	}
+}
+
+func TestMetricsObserveRetries(t *testing.T) {
+	var hits int32
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	m := &fakeMetrics{}
+	limiter := NewRateLimiter(100, 10, LimitWait)
+	limiter.RetryThrottled = 1
+	api.Wallets.basic.Metrics = m
+	api.Wallets.basic.Limiter = limiter
+
+	if _, err := api.Wallets.List(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"GetWallets 429", "GetWallets 200"}; !reflect.DeepEqual(m.requests, want) {
+		t.Errorf("requests %q, want %q", m.requests, want)
+	}
+	if want := []string{"GetWallets"}; !reflect.DeepEqual(m.retries, want) {
+		t.Errorf("retries %q, want %q", m.retries, want)
+	}
+}
+
+func TestMetricsObserveWebhooks(t *testing.T) {
+	m := &fakeMetrics{}
+	basic := NewBasicAuth("merchant-id", "api-key", true)
+	basic.Metrics = m
+	api := NewAPI(nil, basic)
+
+	h := api.Webhooks.Handler(func(ctx context.Context, e WebhookEvent) error {
+		if e.EventTypeID == 1797 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	for _, body := range []string{`{"EventTypeId":1796}`, `{"EventTypeId":1797}`, `{`} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/webhooks", strings.NewReader(body)))
+	}
+
+	want := []string{"1796 true", "1797 false", "0 false"}
+	if !reflect.DeepEqual(m.webhooks, want) {
+		t.Errorf("webhooks %q, want %q", m.webhooks, want)
+	}
+}
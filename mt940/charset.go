@@ -0,0 +1,68 @@
+package mt940
+
+import (
+	"strings"
+	"unicode"
+)
+
+// transliterations maps the letters Viva descriptions and names commonly hold to the
+// SWIFT X character set, Greek following ELOT 743.
+var transliterations = map[rune]string{
+	'Α': "A", 'Ά': "A", 'Β': "V", 'Γ': "G", 'Δ': "D", 'Ε': "E", 'Έ': "E", 'Ζ': "Z",
+	'Η': "I", 'Ή': "I", 'Θ': "TH", 'Ι': "I", 'Ί': "I", 'Ϊ': "I", 'Κ': "K", 'Λ': "L",
+	'Μ': "M", 'Ν': "N", 'Ξ': "X", 'Ο': "O", 'Ό': "O", 'Π': "P", 'Ρ': "R", 'Σ': "S",
+	'Τ': "T", 'Υ': "Y", 'Ύ': "Y", 'Ϋ': "Y", 'Φ': "F", 'Χ': "CH", 'Ψ': "PS", 'Ω': "O",
+	'Ώ': "O",
+	'α': "a", 'ά': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'έ': "e", 'ζ': "z",
+	'η': "i", 'ή': "i", 'θ': "th", 'ι': "i", 'ί': "i", 'ϊ': "i", 'ΐ': "i", 'κ': "k",
+	'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'ό': "o", 'π': "p", 'ρ': "r",
+	'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'ύ': "y", 'ϋ': "y", 'ΰ': "y", 'φ': "f",
+	'χ': "ch", 'ψ': "ps", 'ω': "o", 'ώ': "o",
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Æ': "AE", 'Ç': "C",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I",
+	'Ñ': "N", 'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O", 'Ù': "U",
+	'Ú': "U", 'Û': "U", 'Ü': "U", 'Ý': "Y", 'ß': "ss",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae", 'ç': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ù': "u",
+	'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'ÿ': "y",
+	'Ą': "A", 'ą': "a", 'Č': "C", 'č': "c", 'Ć': "C", 'ć': "c", 'Ď': "D", 'ď': "d",
+	'Ę': "E", 'ę': "e", 'Ě': "E", 'ě': "e", 'Ł': "L", 'ł': "l", 'Ń': "N", 'ń': "n",
+	'Ň': "N", 'ň': "n", 'Ő': "O", 'ő': "o", 'Ř': "R", 'ř': "r", 'Ś': "S", 'ś': "s",
+	'Š': "S", 'š': "s", 'Ș': "S", 'ș': "s", 'Ş': "S", 'ş': "s", 'Ť': "T", 'ť': "t",
+	'Ț': "T", 'ț': "t", 'Ţ': "T", 'ţ': "t", 'Ů': "U", 'ů': "u", 'Ű': "U", 'ű': "u",
+	'Ź': "Z", 'ź': "z", 'Ż': "Z", 'ż': "z", 'Ž': "Z", 'ž': "z", 'Ă': "A", 'ă': "a",
+	'€': "EUR",
+}
+
+// replacement stands for the characters which cannot be written.
+const replacement = '.'
+
+// swiftText maps s to the SWIFT X character set, i.e. the latin letters, the digits,
+// the space and / - ? : ( ) . , ' +. Other letters are transliterated when possible and
+// the remaining characters are replaced by a dot.
+func swiftText(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case strings.ContainsRune(" /-?:().,'+", r):
+			b.WriteRune(r)
+		case r == '\t' || r == '\r' || r == '\n':
+			b.WriteRune(' ')
+		default:
+			if t, ok := transliterations[r]; ok {
+				// Capitals written with two letters are title cased within a word, e.g. Θεσσαλονίκη.
+				if len(t) > 1 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+					t = t[:1] + strings.ToLower(t[1:])
+				}
+				b.WriteString(t)
+			} else {
+				b.WriteRune(replacement)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+// Package mt940 writes and parses MT940 bank statements, e.g. to import the statements
+// of Viva wallets into an ERP.
+//
+//	st, err := api.Wallets.Statement(ctx, wallet, from, to)
+//	err = mt940.Write(w, mt940.FromStatement(*st))
+package mt940
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+// Statement is an MT940 statement. Amounts are signed cents, credits being positive.
+type Statement struct {
+	TransactionReference string
+	AccountID            string
+	StatementNumber      string
+	OpeningBalance       Balance
+	ClosingBalance       Balance
+	Entries              []Entry
+}
+
+// Balance is the opening or closing balance of a statement.
+type Balance struct {
+	Date     time.Time
+	Currency string
+	Amount   int64
+}
+
+// Entry is a statement line and its information to the account owner.
+type Entry struct {
+	ValueDate     time.Time
+	EntryDate     time.Time
+	Amount        int64
+	TypeCode      string // e.g. TRF, written with the N prefix of customer transactions
+	Reference     string
+	BankReference string
+	Description   string
+}
+
+// Transaction type code of the entries built from account transactions, i.e. a transfer.
+const transferTypeCode = "TRF"
+
+// noReference is the customer reference of the entries without one.
+const noReference = "NONREF"
+
+// FromStatement builds the MT940 statement of a wallet. The account is identified by
+// its IBAN, or its id when it has none.
+func FromStatement(s vivawallet.Statement) Statement {
+	currency := Currency(s.Wallet.CurrencyCode)
+
+	account := s.Wallet.IBAN
+	if account == "" {
+		account = strconv.Itoa(s.Wallet.WalletID)
+	}
+
+	st := Statement{
+		TransactionReference: s.To.Format("20060102"),
+		AccountID:            account,
+		StatementNumber:      fmt.Sprintf("%05d/001", s.To.YearDay()),
+		OpeningBalance:       Balance{Date: s.From, Currency: currency, Amount: cents(s.OpeningBalance)},
+		ClosingBalance:       Balance{Date: s.To, Currency: currency, Amount: cents(s.ClosingBalance)},
+	}
+
+	for _, t := range s.Transactions {
+		valueDate := t.ValueDate
+		if valueDate.IsZero() {
+			valueDate = t.Date
+		}
+		reference := t.Reference
+		if reference == "" {
+			reference = noReference
+		}
+
+		st.Entries = append(st.Entries, Entry{
+			ValueDate:     valueDate,
+			EntryDate:     t.Date,
+			Amount:        cents(t.Amount),
+			TypeCode:      transferTypeCode,
+			Reference:     reference,
+			BankReference: t.TransactionID,
+			Description:   t.Description,
+		})
+	}
+	return st
+}
+
+// currencies maps the numeric ISO 4217 codes used by Viva to the alphabetic ones of
+// MT940.
+var currencies = map[string]string{
+	"203": "CZK",
+	"208": "DKK",
+	"348": "HUF",
+	"752": "SEK",
+	"756": "CHF",
+	"826": "GBP",
+	"840": "USD",
+	"946": "RON",
+	"975": "BGN",
+	"978": "EUR",
+	"985": "PLN",
+}
+
+// Currency returns the alphabetic code of a currency given its numeric or alphabetic
+// ISO 4217 code.
+func Currency(code string) string {
+	if c, ok := currencies[code]; ok {
+		return c
+	}
+	return code
+}
+
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
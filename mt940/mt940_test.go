@@ -0,0 +1,133 @@
+package mt940
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestWriteParseRoundTrip(t *testing.T) {
+	statements := []Statement{
+		{
+			TransactionReference: "20261019",
+			AccountID:            "GR7570100000000000000000000",
+			StatementNumber:      "00292/001",
+			OpeningBalance:       Balance{Date: date(2026, 10, 1), Currency: "EUR", Amount: 125050},
+			ClosingBalance:       Balance{Date: date(2026, 10, 19), Currency: "EUR", Amount: -2500},
+			Entries: []Entry{
+				{
+					ValueDate:     date(2026, 10, 2),
+					EntryDate:     date(2026, 10, 2),
+					Amount:        -127550,
+					TypeCode:      "TRF",
+					Reference:     "NONREF",
+					BankReference: "5c9a8f9f-1d0e",
+					Description:   "Payout to GR7570100000000000000000000 (October) - sales of the first week of the month",
+				},
+				{
+					ValueDate:     date(2026, 12, 31),
+					EntryDate:     date(2027, 1, 2),
+					Amount:        1,
+					TypeCode:      "TRF",
+					Reference:     "INV-2026/001",
+					BankReference: "0f6c7b2e",
+					Description:   "Refund",
+				},
+			},
+		},
+		{
+			TransactionReference: "20261020",
+			AccountID:            "722428129431",
+			StatementNumber:      "00293/001",
+			OpeningBalance:       Balance{Date: date(2026, 10, 20), Currency: "EUR", Amount: 0},
+			ClosingBalance:       Balance{Date: date(2026, 10, 20), Currency: "EUR", Amount: 0},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, statements...); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, statements) {
+		t.Errorf("Parse(Write()) =\n%+v\nwant\n%+v", parsed, statements)
+	}
+}
+
+func TestWriteSwiftCharacters(t *testing.T) {
+	description := strings.Repeat("Πληρωμή καφέ € ", 40)
+	st := Statement{
+		TransactionReference: "Παραγγελία-1234567890",
+		OpeningBalance:       Balance{Date: date(2026, 10, 1), Currency: "EUR"},
+		ClosingBalance:       Balance{Date: date(2026, 10, 1), Currency: "EUR"},
+		Entries: []Entry{{
+			ValueDate:     date(2026, 10, 1),
+			EntryDate:     date(2026, 10, 1),
+			TypeCode:      "TRF",
+			Reference:     "Ωμέγα-ρεφερενς-123",
+			BankReference: "Ψ",
+			Description:   description,
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, st); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if swiftText(line) != line {
+			t.Errorf("line %q is not in the SWIFT X character set", line)
+		}
+		if len(line) > 4+maxDescriptionLineSize {
+			t.Errorf("line %q is too long", line)
+		}
+	}
+
+	parsed, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed[0].TransactionReference; got != "Paraggelia-12345" {
+		t.Errorf("transaction reference %q, want Paraggelia-12345", got)
+	}
+	e := parsed[0].Entries[0]
+	if e.Reference != "Omega-referens-1" || e.BankReference != "PS" {
+		t.Errorf("references %q and %q, want Omega-referens-1 and PS", e.Reference, e.BankReference)
+	}
+	want := []rune(swiftText(description))[:maxDescriptionLines*maxDescriptionLineSize]
+	if e.Description != string(want) {
+		t.Errorf("description %q, want %q", e.Description, string(want))
+	}
+}
+
+func TestSwiftText(t *testing.T) {
+	tests := map[string]string{
+		"Καφές & τσάι":        "Kafes . tsai",
+		"Ψωμί, Θεσσαλονίκη":   "Psomi, Thessaloniki",
+		"Crème brûlée 5€":     "Creme brulee 5EUR",
+		"Łódź\tNr. 7/8 (a+b)": "Lodz Nr. 7/8 (a+b)",
+		"日本":                  "..",
+	}
+	for in, want := range tests {
+		if got := swiftText(in); got != want {
+			t.Errorf("swiftText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWrapContinuationLines(t *testing.T) {
+	s := strings.Repeat("a", maxDescriptionLineSize) + "-b" + strings.Repeat("c", maxDescriptionLineSize-2) + ":d"
+	lines := wrap(s)
+	if len(lines) != 3 || lines[1][0] != replacement || lines[2][0] != replacement {
+		t.Errorf("wrap() = %q, want the continuation lines not to start with - or :", lines)
+	}
+}
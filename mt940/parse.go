@@ -0,0 +1,197 @@
+package mt940
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	balancePattern = regexp.MustCompile(`^([CD])(\d{6})([A-Z]{3})(\d+,\d{0,2})$`)
+	entryPattern   = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])([A-Z])?(\d+,\d{0,2})([A-Z][A-Z0-9]{3})(.*?)(?://(.*))?$`)
+)
+
+// Parse reads the statements of an MT940 file.
+func Parse(r io.Reader) ([]Statement, error) {
+	var (
+		statements []Statement
+		current    *Statement
+		tag        string
+		value      []string
+	)
+
+	flush := func() error {
+		if tag == "" {
+			return nil
+		}
+		if current == nil {
+			current = &Statement{}
+		}
+		err := parseField(current, tag, value)
+		tag, value = "", nil
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		switch {
+		case text == "-" || text == "-}":
+			if err := flush(); err != nil {
+				return nil, fmt.Errorf("line %d: %s", line, err)
+			}
+			if current != nil {
+				statements = append(statements, *current)
+				current = nil
+			}
+		case strings.HasPrefix(text, ":"):
+			if err := flush(); err != nil {
+				return nil, fmt.Errorf("line %d: %s", line, err)
+			}
+			end := strings.Index(text[1:], ":")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: invalid field %s", line, text)
+			}
+			tag = text[1 : end+1]
+			value = []string{text[end+2:]}
+		case tag != "":
+			value = append(value, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if current != nil {
+		statements = append(statements, *current)
+	}
+	return statements, nil
+}
+
+func parseField(s *Statement, tag string, value []string) error {
+	switch tag {
+	case "20":
+		s.TransactionReference = value[0]
+	case "25":
+		s.AccountID = value[0]
+	case "28C":
+		s.StatementNumber = value[0]
+	case "60F", "60M":
+		b, err := parseBalance(value[0])
+		if err != nil {
+			return err
+		}
+		s.OpeningBalance = b
+	case "62F", "62M":
+		b, err := parseBalance(value[0])
+		if err != nil {
+			return err
+		}
+		s.ClosingBalance = b
+	case "61":
+		e, err := parseEntry(value[0])
+		if err != nil {
+			return err
+		}
+		s.Entries = append(s.Entries, e)
+	case "86":
+		if len(s.Entries) > 0 {
+			s.Entries[len(s.Entries)-1].Description = strings.Join(value, "")
+		}
+	}
+	return nil
+}
+
+func parseBalance(v string) (Balance, error) {
+	m := balancePattern.FindStringSubmatch(v)
+	if m == nil {
+		return Balance{}, fmt.Errorf("invalid balance %s", v)
+	}
+
+	date, err := time.Parse("060102", m[2])
+	if err != nil {
+		return Balance{}, fmt.Errorf("invalid balance date %s", m[2])
+	}
+	amount, err := parseAmount(m[4], m[1])
+	if err != nil {
+		return Balance{}, err
+	}
+	return Balance{Date: date, Currency: m[3], Amount: amount}, nil
+}
+
+func parseEntry(v string) (Entry, error) {
+	m := entryPattern.FindStringSubmatch(v)
+	if m == nil {
+		return Entry{}, fmt.Errorf("invalid statement line %s", v)
+	}
+
+	valueDate, err := time.Parse("060102", m[1])
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid value date %s", m[1])
+	}
+
+	entryDate := valueDate
+	if m[2] != "" {
+		d, err := time.Parse("0102", m[2])
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid entry date %s", m[2])
+		}
+		entryDate = time.Date(valueDate.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+		// Entries booked in january for a value date in december belong to the next year.
+		if entryDate.Before(valueDate.AddDate(0, -6, 0)) {
+			entryDate = entryDate.AddDate(1, 0, 0)
+		}
+	}
+
+	mark := strings.TrimPrefix(m[3], "R")
+	if strings.HasPrefix(m[3], "R") {
+		// Reversals invert the direction of the entry.
+		if mark == "C" {
+			mark = "D"
+		} else {
+			mark = "C"
+		}
+	}
+	amount, err := parseAmount(m[5], mark)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return Entry{
+		ValueDate:     valueDate,
+		EntryDate:     entryDate,
+		Amount:        amount,
+		TypeCode:      m[6][1:],
+		Reference:     m[7],
+		BankReference: m[8],
+	}, nil
+}
+
+// parseAmount reads an amount with a decimal comma in signed cents.
+func parseAmount(v string, mark string) (int64, error) {
+	parts := strings.SplitN(v, ",", 2)
+	units, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %s", v)
+	}
+
+	decimals := (parts[1] + "00")[:2]
+	c, err := strconv.ParseInt(decimals, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %s", v)
+	}
+
+	amount := units*100 + c
+	if mark == "D" {
+		amount = -amount
+	}
+	return amount, nil
+}
@@ -0,0 +1,105 @@
+package mt940
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxReferenceLength is the length of the customer and bank references of an entry.
+const (
+	maxReferenceLength     = 16
+	maxDescriptionLines    = 6
+	maxDescriptionLineSize = 65
+)
+
+// Write writes the statements in MT940 format, separated by a line holding a dash.
+func Write(w io.Writer, statements ...Statement) error {
+	bw := bufio.NewWriter(w)
+	for _, s := range statements {
+		writeStatement(bw, s)
+	}
+	return bw.Flush()
+}
+
+func writeStatement(w *bufio.Writer, s Statement) {
+	fmt.Fprintf(w, ":20:%s\r\n", truncate(swiftText(s.TransactionReference), maxReferenceLength))
+	fmt.Fprintf(w, ":25:%s\r\n", swiftText(s.AccountID))
+	fmt.Fprintf(w, ":28C:%s\r\n", swiftText(s.StatementNumber))
+	fmt.Fprintf(w, ":60F:%s\r\n", formatBalance(s.OpeningBalance))
+
+	for _, e := range s.Entries {
+		fmt.Fprintf(w, ":61:%s\r\n", formatEntry(e))
+		if e.Description != "" {
+			fmt.Fprintf(w, ":86:%s\r\n", strings.Join(wrap(swiftText(e.Description)), "\r\n"))
+		}
+	}
+
+	fmt.Fprintf(w, ":62F:%s\r\n", formatBalance(s.ClosingBalance))
+	fmt.Fprint(w, "-\r\n")
+}
+
+func formatBalance(b Balance) string {
+	return fmt.Sprintf("%s%s%s%s", mark(b.Amount), b.Date.Format("060102"), b.Currency, formatAmount(b.Amount))
+}
+
+func formatEntry(e Entry) string {
+	line := fmt.Sprintf("%s%s%s%sN%s%s",
+		e.ValueDate.Format("060102"),
+		e.EntryDate.Format("0102"),
+		mark(e.Amount),
+		formatAmount(e.Amount),
+		truncate(swiftText(e.TypeCode), 3),
+		truncate(swiftText(e.Reference), maxReferenceLength),
+	)
+	if e.BankReference != "" {
+		line += "//" + truncate(swiftText(e.BankReference), maxReferenceLength)
+	}
+	return line
+}
+
+func mark(amount int64) string {
+	if amount < 0 {
+		return "D"
+	}
+	return "C"
+}
+
+// formatAmount writes the absolute value of an amount in cents with a decimal comma.
+func formatAmount(amount int64) string {
+	if amount < 0 {
+		amount = -amount
+	}
+	return fmt.Sprintf("%d,%02d", amount/100, amount%100)
+}
+
+// truncate cuts s to n characters.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// wrap splits a description in the lines allowed by the :86: field. A continuation line
+// starting with a colon or a dash would be read as a new field or the end of the
+// statement, so the character is replaced.
+func wrap(s string) []string {
+	var lines []string
+	r := []rune(s)
+	for len(r) > 0 && len(lines) < maxDescriptionLines {
+		n := len(r)
+		if n > maxDescriptionLineSize {
+			n = maxDescriptionLineSize
+		}
+		line := r[:n]
+		if len(lines) > 0 && (line[0] == ':' || line[0] == '-') {
+			line = append([]rune{replacement}, line[1:]...)
+		}
+		lines = append(lines, string(line))
+		r = r[n:]
+	}
+	return lines
+}
@@ -0,0 +1,188 @@
+package vivawallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// DigitalWallet identifies the digital wallet a transaction was paid with, as reported
+// by the DigitalWalletID of the transactions.
+type DigitalWallet int
+
+// Digital wallets supported by Native Checkout.
+const (
+	DigitalWalletNone      DigitalWallet = 0
+	DigitalWalletApplePay  DigitalWallet = 3
+	DigitalWalletGooglePay DigitalWallet = 4
+)
+
+func (w DigitalWallet) String() string {
+	switch w {
+	case DigitalWalletNone:
+		return "None"
+	case DigitalWalletApplePay:
+		return "Apple Pay"
+	case DigitalWalletGooglePay:
+		return "Google Pay"
+	}
+	return fmt.Sprintf("DigitalWallet(%d)", int(w))
+}
+
+// DigitalWallet returns the digital wallet the transaction was paid with.
+func (t GetTransactionResponse) DigitalWallet() DigitalWallet {
+	return DigitalWallet(t.DigitalWalletID)
+}
+
+// DigitalWallet returns the digital wallet the transaction was paid with.
+func (r ReportRow) DigitalWallet() DigitalWallet {
+	return DigitalWallet(r.DigitalWalletID)
+}
+
+// NativeCheckoutService handles the Native Checkout endpoints, used to charge your
+// customers from your own payment form.
+type NativeCheckoutService service
+
+// WalletChargeToken holds the payment data returned by Apple Pay or Google Pay on the
+// device of the customer.
+type WalletChargeToken struct {
+	Amount int64         `json:"amount"`
+	Wallet DigitalWallet `json:"digitalWalletId"`
+	// Token is the payment data as returned by the wallet, i.e. the `paymentData` of an
+	// Apple Pay payment or the `paymentMethodData.tokenizationData.token` of a Google
+	// Pay one.
+	Token      json.RawMessage `json:"token"`
+	SourceCode string          `json:"sourceCode,omitempty"`
+}
+
+type ChargeTokenResponse struct {
+	ChargeToken string `json:"chargeToken"`
+	// RedirectToACSForm is the html form redirecting the customer to the 3-D Secure
+	// challenge of the issuer, if any.
+	RedirectToACSForm string `json:"redirectToACSForm,omitempty"`
+}
+
+// CreateWalletChargeToken creates a charge token from the payment data of Apple Pay or
+// Google Pay. The token is then used to Charge the customer.
+// Ref: https://developer.vivawallet.com/online-checkouts/native-checkout/
+func (s *NativeCheckoutService) CreateWalletChargeToken(ctx context.Context, payload WalletChargeToken) (*ChargeTokenResponse, error) {
+	ctx = withOperation(ctx, "CreateWalletChargeToken")
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := service(*s).oauthClient(ctx, ScopeNativeCheckout)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := walletChargeTokenUri(c.Config)
+	body, err := jsonBody(payload, "charge token")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &ChargeTokenResponse{}
+	reqErr := c.do(ctx, "POST", uri, body, t)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	return t, nil
+}
+
+func walletChargeTokenUri(c Config) string {
+	return fmt.Sprintf("%s/nativecheckout/v2/chargetokens:digitalwallets", ApiUri(c))
+}
+
+// ApplePayMerchantValidation holds the validation url Apple Pay passes to the
+// `onvalidatemerchant` handler of the payment session.
+type ApplePayMerchantValidation struct {
+	ValidationURL string `json:"validationUrl"`
+	DomainName    string `json:"domainName"`
+	DisplayName   string `json:"displayName,omitempty"`
+}
+
+// ValidateApplePayMerchant requests a merchant session for the Apple Pay payment
+// session of the customer. The session is opaque and is passed as is to
+// `completeMerchantValidation` on the device.
+// Ref: https://developer.vivawallet.com/online-checkouts/native-checkout/
+func (s *NativeCheckoutService) ValidateApplePayMerchant(ctx context.Context, payload ApplePayMerchantValidation) (json.RawMessage, error) {
+	ctx = withOperation(ctx, "ValidateApplePayMerchant")
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := service(*s).oauthClient(ctx, ScopeNativeCheckout)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := applePaySessionUri(c.Config)
+	body, err := jsonBody(payload, "merchant validation")
+	if err != nil {
+		return nil, err
+	}
+
+	var session json.RawMessage
+	reqErr := c.do(ctx, "POST", uri, body, &session)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	return session, nil
+}
+
+func applePaySessionUri(c Config) string {
+	return fmt.Sprintf("%s/nativecheckout/v2/applepay/merchantsession", ApiUri(c))
+}
+
+type Charge struct {
+	Amount       int64    `json:"amount"`
+	ChargeToken  string   `json:"chargeToken"`
+	PreAuth      bool     `json:"preauth,omitempty"`
+	Installments int      `json:"installments,omitempty"`
+	TipAmount    int64    `json:"tipAmount,omitempty"`
+	SourceCode   string   `json:"sourceCode,omitempty"`
+	MerchantTrns string   `json:"merchantTrns,omitempty"`
+	CustomerTrns string   `json:"customerTrns,omitempty"`
+	CurrencyCode int      `json:"currencyCode,omitempty"`
+	Customer     Customer `json:"customer,omitempty"`
+}
+
+type ChargeResponse struct {
+	TransactionID string `json:"transactionId"`
+}
+
+// Charge charges the customer with a charge token. When the token required a 3-D Secure
+// challenge, the charge is made once the customer is back on the SessionRedirectURL.
+// See WithIdempotencyKey to avoid charging twice when retrying.
+// Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Native-Checkout-v2
+func (s *NativeCheckoutService) Charge(ctx context.Context, payload Charge) (*ChargeResponse, error) {
+	ctx = withOperation(ctx, "Charge")
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := service(*s).oauthClient(ctx, ScopeNativeCheckout)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := chargeUri(c.Config)
+	body, err := jsonBody(payload, "charge")
+	if err != nil {
+		return nil, err
+	}
+
+	return idempotent(ctx, c.Idempotency, c.Config.ClientID, "Charge", func(ctx context.Context) (*ChargeResponse, error) {
+		r := &ChargeResponse{}
+		reqErr := c.do(ctx, "POST", uri, body, r)
+		if reqErr != nil {
+			return nil, reqErr
+		}
+		return r, nil
+	}, nil)
+}
+
+func chargeUri(c Config) string {
+	return fmt.Sprintf("%s/nativecheckout/v2/transactions", ApiUri(c))
+}
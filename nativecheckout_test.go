@@ -0,0 +1,201 @@
+package vivawallet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// grantScopes gives the OAuth client of the api a token granted the given scopes.
+func grantScopes(t *testing.T, api *API, scopes ...string) {
+	t.Helper()
+	c := api.NativeCheckout.oauth
+	if err := c.Tokens.Store(c.tokenKey(), Token{Value: "token", Expires: time.Now().Add(time.Hour), Scopes: scopes}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateWalletChargeToken(t *testing.T) {
+	rec := &recorder{body: `{"chargeToken":"ctok_1"}`}
+	api := newTestAPI(t, rec.handle)
+
+	payload := WalletChargeToken{
+		Amount:     1000,
+		Wallet:     DigitalWalletGooglePay,
+		Token:      json.RawMessage(`{"signature":"sig","protocolVersion":"ECv2"}`),
+		SourceCode: "1234",
+	}
+	r, err := api.NativeCheckout.CreateWalletChargeToken(context.Background(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ChargeToken != "ctok_1" || r.ChallengeRequired() {
+		t.Errorf("CreateWalletChargeToken() = %+v, want ctok_1 without challenge", r)
+	}
+
+	req := rec.only(t)
+	if req.method != "POST" || req.path != "/nativecheckout/v2/chargetokens:digitalwallets" {
+		t.Errorf("sent %s %s", req.method, req.path)
+	}
+	want := map[string]interface{}{
+		"amount":          float64(1000),
+		"digitalWalletId": float64(4),
+		"token":           map[string]interface{}{"signature": "sig", "protocolVersion": "ECv2"},
+		"sourceCode":      "1234",
+	}
+	if !reflect.DeepEqual(req.body, want) {
+		t.Errorf("sent %v, want %v", req.body, want)
+	}
+}
+
+func TestValidateApplePayMerchant(t *testing.T) {
+	session := `{"epochTimestamp":1697700000000,"merchantSessionIdentifier":"SSH1","signature":"sig"}`
+	rec := &recorder{body: session}
+	api := newTestAPI(t, rec.handle)
+
+	r, err := api.NativeCheckout.ValidateApplePayMerchant(context.Background(), ApplePayMerchantValidation{
+		ValidationURL: "https://apple-pay-gateway.apple.com/paymentservices/startSession",
+		DomainName:    "shop.example.com",
+		DisplayName:   "Shop",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(r) != session {
+		t.Errorf("ValidateApplePayMerchant() = %s, want the session as is", r)
+	}
+
+	req := rec.only(t)
+	if req.method != "POST" || req.path != "/nativecheckout/v2/applepay/merchantsession" {
+		t.Errorf("sent %s %s", req.method, req.path)
+	}
+	want := map[string]interface{}{
+		"validationUrl": "https://apple-pay-gateway.apple.com/paymentservices/startSession",
+		"domainName":    "shop.example.com",
+		"displayName":   "Shop",
+	}
+	if !reflect.DeepEqual(req.body, want) {
+		t.Errorf("sent %v, want %v", req.body, want)
+	}
+}
+
+func TestCharge(t *testing.T) {
+	rec := &recorder{body: `{"transactionId":"trx-1"}`}
+	api := newTestAPI(t, rec.handle)
+
+	r, err := api.NativeCheckout.Charge(context.Background(), Charge{
+		Amount:       1000,
+		ChargeToken:  "ctok_1",
+		PreAuth:      true,
+		MerchantTrns: "INV-1",
+		Customer:     Customer{Email: "user@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.TransactionID != "trx-1" {
+		t.Errorf("Charge() = %+v, want trx-1", r)
+	}
+
+	req := rec.only(t)
+	if req.method != "POST" || req.path != "/nativecheckout/v2/transactions" {
+		t.Errorf("sent %s %s", req.method, req.path)
+	}
+	want := map[string]interface{}{
+		"amount":       float64(1000),
+		"chargeToken":  "ctok_1",
+		"preauth":      true,
+		"merchantTrns": "INV-1",
+		"customer":     map[string]interface{}{"email": "user@example.com"},
+	}
+	if !reflect.DeepEqual(req.body, want) {
+		t.Errorf("sent %v, want %v", req.body, want)
+	}
+}
+
+func TestNativeCheckoutErrors(t *testing.T) {
+	calls := map[string]func(api *API) error{
+		"wallet charge token": func(api *API) error {
+			_, err := api.NativeCheckout.CreateWalletChargeToken(context.Background(), WalletChargeToken{Amount: 1000, Wallet: DigitalWalletApplePay, Token: json.RawMessage(`{}`)})
+			return err
+		},
+		"apple pay merchant": func(api *API) error {
+			_, err := api.NativeCheckout.ValidateApplePayMerchant(context.Background(), ApplePayMerchantValidation{ValidationURL: "https://apple.com/session", DomainName: "shop.example.com"})
+			return err
+		},
+		"charge": func(api *API) error {
+			_, err := api.NativeCheckout.Charge(context.Background(), Charge{Amount: 1000, ChargeToken: "ctok_1"})
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name+" status", func(t *testing.T) {
+			rec := &recorder{status: http.StatusBadRequest, body: `{"message":"invalid"}`}
+			if err := call(newTestAPI(t, rec.handle)); err == nil || !strings.Contains(err.Error(), "status 400") {
+				t.Errorf("error %v, want the status", err)
+			}
+		})
+
+		t.Run(name+" response", func(t *testing.T) {
+			rec := &recorder{body: `{"chargeToken":`}
+			if err := call(newTestAPI(t, rec.handle)); err == nil {
+				t.Error("succeeded with a truncated response")
+			}
+		})
+
+		t.Run(name+" scope", func(t *testing.T) {
+			rec := &recorder{}
+			api := newTestAPI(t, rec.handle)
+			grantScopes(t, api, ScopeRedirectCheckout)
+
+			var scopeErr *ScopeError
+			if err := call(api); !errors.As(err, &scopeErr) || scopeErr.Required != ScopeNativeCheckout {
+				t.Errorf("error %v, want the missing native checkout scope", err)
+			}
+			if rec.count() != 0 {
+				t.Errorf("sent %d requests without the scope", rec.count())
+			}
+		})
+	}
+}
+
+func TestNativeCheckoutValidation(t *testing.T) {
+	rec := &recorder{}
+	api := newTestAPI(t, rec.handle)
+	ctx := context.Background()
+
+	_, err := api.NativeCheckout.CreateWalletChargeToken(ctx, WalletChargeToken{Amount: 1000, Wallet: DigitalWalletNone})
+	if got, want := invalidFields(t, err), []string{"digitalWalletId", "token"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CreateWalletChargeToken() invalid fields %v, want %v", got, want)
+	}
+
+	_, err = api.NativeCheckout.ValidateApplePayMerchant(ctx, ApplePayMerchantValidation{ValidationURL: "http://apple.com"})
+	if got, want := invalidFields(t, err), []string{"validationUrl", "domainName"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateApplePayMerchant() invalid fields %v, want %v", got, want)
+	}
+
+	_, err = api.NativeCheckout.Charge(ctx, Charge{Amount: 1000})
+	if got, want := invalidFields(t, err), []string{"chargeToken"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Charge() invalid fields %v, want %v", got, want)
+	}
+
+	if rec.count() != 0 {
+		t.Errorf("sent %d invalid requests", rec.count())
+	}
+}
+
+func TestDigitalWallet(t *testing.T) {
+	trx := GetTransactionResponse{DigitalWalletID: 3}
+	if trx.DigitalWallet() != DigitalWalletApplePay || trx.DigitalWallet().String() != "Apple Pay" {
+		t.Errorf("DigitalWallet() = %s, want Apple Pay", trx.DigitalWallet())
+	}
+	if s := DigitalWallet(9).String(); s != "DigitalWallet(9)" {
+		t.Errorf("unknown wallet printed as %s", s)
+	}
+}
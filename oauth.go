@@ -2,76 +2,85 @@ package vivawallet
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"sync"
+	"net/url"
+	"strings"
 	"time"
 )
 
 // New creates a new viva client for the oauth apis
-func NewOAuth(clientID string, clientSecret string, demo bool) *OAuthClient {
+func NewOAuth(clientID string, clientSecret string, demo bool, opts ...Option) *OAuthClient {
+	o := newClientOptions(opts)
 	return &OAuthClient{
 		Config: Config{
 			Demo:         demo,
 			ClientID:     clientID,
 			ClientSecret: clientSecret,
+			BaseURL:      o.baseURL,
 		},
-		Client:     httpClient,
-		tokenValue: &token{},
-		lock:       &sync.RWMutex{},
+		Client:    o.httpClient(),
+		UserAgent: o.userAgent,
+		Tokens:    NewMemoryTokenStore(),
+		flight:    &authFlight{},
 	}
 }
 
+// NewOAuthFromConfig creates a new viva client for the oauth apis from a loaded Config,
+// including its scopes and base url. Options given take precedence over the Config.
+func NewOAuthFromConfig(c Config, opts ...Option) *OAuthClient {
+	opts = append([]Option{WithBaseURL(c.BaseURL)}, opts...)
+	client := NewOAuth(c.ClientID, c.ClientSecret, c.Demo, opts...)
+	client.Config.Scopes = c.Scopes
+	return client
+}
+
 func (c OAuthClient) Post(uri string, reader *bytes.Reader, v interface{}) error {
-	req := newRequest("POST", uri, reader)
-	return c.performReq(req, v)
+	return c.do(context.Background(), "POST", uri, reader, v)
 }
 
 func (c OAuthClient) Get(uri string, v interface{}) error {
-	req := newRequest("GET", uri, nil)
-	return c.performReq(req, v)
+	return c.do(context.Background(), "GET", uri, nil, v)
 }
 
 func (c OAuthClient) Patch(uri string, reader *bytes.Reader, v interface{}) error {
-	req := newRequest("PATCH", uri, reader)
-	return c.performReq(req, v)
+	return c.do(context.Background(), "PATCH", uri, reader, v)
 }
 
 func (c OAuthClient) Delete(uri string, reader *bytes.Reader, v interface{}) error {
-	req := newRequest("DELETE", uri, reader)
+	return c.do(context.Background(), "DELETE", uri, reader, v)
+}
+
+func (c OAuthClient) do(ctx context.Context, method string, uri string, reader *bytes.Reader, v interface{}) error {
+	req := newRequest(ctx, method, uri, reader)
 	return c.performReq(req, v)
 }
 
 func (c OAuthClient) setBearerToken(req *http.Request) {
 	req.Header.Set("Authorization", "Bearer "+c.AuthToken())
+	setUserAgent(req, c.UserAgent)
 }
 
 func (c OAuthClient) performReq(req *http.Request, v interface{}) error {
 	req.Header.Add("Content-Type", "application/json")
 	c.setBearerToken(req)
 
-	resp, httpErr := c.Client.Do(req)
+	resp, body, httpErr := send(c.Client, c.hooks(), req)
 	if httpErr != nil {
 		return fmt.Errorf("failed to perform request %s", httpErr)
 	}
 
-	body, bodyErr := io.ReadAll(resp.Body)
-	if bodyErr == nil {
-		resp.Body.Close()
-	}
-
-	if bodyErr != nil {
-		return bodyErr
-	}
-
 	if resp.StatusCode != 200 {
 		return fmt.Errorf("failed to perform request with status %d", resp.StatusCode)
 	}
 
+	if v == nil {
+		return nil
+	}
 	return json.Unmarshal(body, v)
 }
 
@@ -84,16 +93,34 @@ type TokenResponse struct {
 
 // Authenticate retrieves the access token to continue making requests to Viva's API. It
 // returns the full response of the API and stores the token and expiration time for
-// later use.
+// later use. Concurrent calls share a single token request.
 func (c OAuthClient) Authenticate() (*TokenResponse, error) {
+	return c.flight.do(context.Background(), c.authenticate)
+}
+
+func (c OAuthClient) authenticate(ctx context.Context) (*TokenResponse, error) {
+	response, err := c.requestToken(ctx)
+	if c.Metrics != nil {
+		c.Metrics.ObserveTokenRefresh(err)
+	}
+	return response, err
+}
+
+func (c OAuthClient) requestToken(ctx context.Context) (*TokenResponse, error) {
+	ctx = withOperation(ctx, "Authenticate")
 	uri := c.tokenEndpoint()
 
-	grant := []byte("grant_type=client_credentials")
-	req, _ := http.NewRequest("POST", uri, bytes.NewBuffer(grant))
+	grant := url.Values{}
+	grant.Set("grant_type", "client_credentials")
+	if len(c.Config.Scopes) > 0 {
+		grant.Set("scope", strings.Join(c.Config.Scopes, " "))
+	}
+	req, _ := http.NewRequestWithContext(ctx, "POST", uri, strings.NewReader(grant.Encode()))
 	req.SetBasicAuth(c.Config.ClientID, c.Config.ClientSecret)
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	setUserAgent(req, c.UserAgent)
 
-	resp, httpErr := c.Client.Do(req)
+	resp, body, httpErr := send(c.Client, c.hooks(), req)
 	if httpErr != nil {
 		return nil, fmt.Errorf("failed to perform access token request %s", httpErr)
 	}
@@ -102,54 +129,85 @@ func (c OAuthClient) Authenticate() (*TokenResponse, error) {
 		return nil, errors.New("non successful response")
 	}
 
-	defer resp.Body.Close()
-	body, bodyErr := io.ReadAll(resp.Body)
-	if bodyErr != nil {
-		return nil, bodyErr
-	}
-
 	response := &TokenResponse{}
 	if jsonErr := json.Unmarshal(body, response); jsonErr != nil {
 		return nil, jsonErr
 	}
 
 	expiry := time.Now().Add(time.Second * time.Duration(response.ExpiresIn))
-	c.SetToken(response.AccessToken, expiry)
+	t := Token{Value: response.AccessToken, Expires: expiry, Scopes: parseScopes(response.Scope)}
+	if storeErr := c.Tokens.Store(c.tokenKey(), t); storeErr != nil {
+		return nil, fmt.Errorf("failed to store access token %s", storeErr)
+	}
 
 	return response, nil
 }
 
-// AuthToken returns the token value
-func (c OAuthClient) AuthToken() string {
-	c.lock.RLock()
-
-	t := c.tokenValue.value
+// ensureAuth authenticates again if the current token has expired. The goroutines
+// finding the token expired at the same time wait for a single authentication.
+func (c OAuthClient) ensureAuth(ctx context.Context) error {
+	if !c.HasAuthExpired() {
+		return nil
+	}
 
-	c.lock.RUnlock()
-	return t
+	_, authErr := c.flight.do(ctx, func(ctx context.Context) (*TokenResponse, error) {
+		// The token may have been refreshed by a call that completed after the check
+		// above.
+		if t, err := c.Tokens.Load(c.tokenKey()); err == nil && t != nil && time.Now().Before(t.Expires) {
+			return tokenResponse(*t), nil
+		}
+		return c.authenticate(ctx)
+	})
+	if authErr != nil {
+		return fmt.Errorf("authentication error %s", authErr)
+	}
+	return nil
 }
 
-// SetToken sets the token value and the expiration time of the token.
-func (c OAuthClient) SetToken(value string, expires time.Time) {
-	c.lock.Lock()
+// tokenResponse describes a stored token as a response of the token endpoint.
+func tokenResponse(t Token) *TokenResponse {
+	return &TokenResponse{
+		AccessToken: t.Value,
+		ExpiresIn:   int64(time.Until(t.Expires) / time.Second),
+		TokenType:   "Bearer",
+		Scope:       strings.Join(t.Scopes, " "),
+	}
+}
 
-	c.tokenValue.value = value
-	c.tokenValue.expires = expires
+// AuthToken returns the token value, or an empty string if there is no token in the
+// token store.
+func (c OAuthClient) AuthToken() string {
+	t, err := c.Tokens.Load(c.tokenKey())
+	if err != nil || t == nil {
+		return ""
+	}
+	return t.Value
+}
 
-	c.lock.Unlock()
+// SetToken sets the token value and the expiration time of the token. Errors of the
+// token store are ignored, use the store directly to handle them.
+func (c OAuthClient) SetToken(value string, expires time.Time) {
+	_ = c.Tokens.Store(c.tokenKey(), Token{Value: value, Expires: expires})
 }
 
 // HasAuthExpired returns true if the expiry time of the token has passed and false
-// otherwise.
+// otherwise. A missing token is considered expired.
 func (c OAuthClient) HasAuthExpired() bool {
-	c.lock.RLock()
-
-	expires := c.tokenValue.expires
-
-	c.lock.RUnlock()
+	t, err := c.Tokens.Load(c.tokenKey())
+	if err != nil || t == nil {
+		return true
+	}
 
 	now := time.Now()
-	return now.After(expires)
+	return now.After(t.Expires)
+}
+
+// tokenKey identifies the credentials of the client in the token store.
+func (c OAuthClient) tokenKey() string {
+	if isDemo(c.Config) {
+		return "demo:" + c.Config.ClientID
+	}
+	return c.Config.ClientID
 }
 
 func AuthBody(c Config) string {
@@ -163,10 +221,13 @@ func BasicAuth(c Config) string {
 }
 
 func (c OAuthClient) tokenEndpoint() string {
-	return fmt.Sprintf("%s/%s", c.authUri(), "/connect/token")
+	return fmt.Sprintf("%s/connect/token", c.authUri())
 }
 
 func (c OAuthClient) authUri() string {
+	if c.Config.BaseURL != "" {
+		return c.Config.BaseURL
+	}
 	if isDemo(c.Config) {
 		return "https://demo-accounts.vivapayments.com"
 	}
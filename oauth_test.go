@@ -0,0 +1,120 @@
+package vivawallet
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const concurrentCalls = 50
+
+// tokenServer serves the token endpoint with the given status, counting the requests.
+// Responses are delayed so that the concurrent calls find the token request in flight.
+func tokenServer(t *testing.T, status int, hits *int32) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/connect/token" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(hits, 1)
+		time.Sleep(100 * time.Millisecond)
+
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			_, _ = w.Write([]byte(`{"access_token":"new-token","expires_in":3600,"token_type":"Bearer"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func expiredClient(srv *httptest.Server) *OAuthClient {
+	c := NewOAuth("client-id", "client-secret", true, WithBaseURL(srv.URL))
+	c.SetToken("old-token", time.Now().Add(-time.Minute))
+	return c
+}
+
+// ensureAuthConcurrently calls ensureAuth from many goroutines at once and returns their
+// errors.
+func ensureAuthConcurrently(c *OAuthClient) []error {
+	var (
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+		errs  = make([]error, concurrentCalls)
+	)
+	for i := 0; i < concurrentCalls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = c.ensureAuth(context.Background())
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+func TestEnsureAuthSharesTokenRequest(t *testing.T) {
+	var hits int32
+	c := expiredClient(tokenServer(t, http.StatusOK, &hits))
+
+	for i, err := range ensureAuthConcurrently(c) {
+		if err != nil {
+			t.Errorf("call %d failed: %s", i, err)
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("token endpoint hit %d times, want 1", n)
+	}
+	if got := c.AuthToken(); got != "new-token" {
+		t.Errorf("token is %q, want new-token", got)
+	}
+}
+
+func TestEnsureAuthSharesFailure(t *testing.T) {
+	var hits int32
+	c := expiredClient(tokenServer(t, http.StatusUnauthorized, &hits))
+
+	for i, err := range ensureAuthConcurrently(c) {
+		if err == nil {
+			t.Errorf("call %d succeeded, want the authentication error", i)
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("token endpoint hit %d times, want 1", n)
+	}
+}
+
+func TestEnsureAuthIgnoresCancellationOfFirstCaller(t *testing.T) {
+	var hits int32
+	c := expiredClient(tokenServer(t, http.StatusOK, &hits))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	first := make(chan error)
+	go func() {
+		first <- c.ensureAuth(ctx)
+	}()
+	// Let the first caller start the token request before the others join it.
+	time.Sleep(5 * time.Millisecond)
+
+	for i, err := range ensureAuthConcurrently(c) {
+		if err != nil {
+			t.Errorf("call %d failed: %s", i, err)
+		}
+	}
+	if err := <-first; err == nil {
+		t.Error("first call succeeded, want its deadline exceeded")
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("token endpoint hit %d times, want 1", n)
+	}
+}
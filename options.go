@@ -0,0 +1,88 @@
+package vivawallet
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a client created by NewOAuth or NewBasicAuth.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	client    *http.Client
+	timeout   time.Duration
+	transport http.RoundTripper
+	userAgent string
+	baseURL   string
+}
+
+// WithHTTPClient sets the http.Client of the client. The client is copied, so that it is
+// never modified by the other options and changing it afterwards, or changing the client
+// of one Viva client, does not affect the others. The copies share its transport and
+// thus its connections.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *clientOptions) {
+		o.client = client
+	}
+}
+
+// WithTimeout sets the timeout of the requests of the client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithTransport sets the transport of the client, e.g. to use a proxy or mTLS.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.transport = transport
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests of the client.
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithBaseURL sends the requests of the client to the given url instead of the Viva
+// hosts, e.g. to use a proxy or a mock server.
+func WithBaseURL(baseURL string) Option {
+	return func(o *clientOptions) {
+		o.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+func newClientOptions(opts []Option) clientOptions {
+	o := clientOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// httpClient returns the http.Client of the options. Every client gets its own
+// http.Client, so that changing it never affects the others.
+func (o clientOptions) httpClient() *http.Client {
+	client := &http.Client{Timeout: defaultTimeout}
+	if o.client != nil {
+		copied := *o.client
+		client = &copied
+	}
+	if o.timeout != 0 {
+		client.Timeout = o.timeout
+	}
+	if o.transport != nil {
+		client.Transport = o.transport
+	}
+	return client
+}
+
+func setUserAgent(req *http.Request, userAgent string) {
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+}
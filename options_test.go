@@ -0,0 +1,87 @@
+package vivawallet
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+type fakeTransport struct{ name string }
+
+func (fakeTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, http.ErrNotSupported
+}
+
+func TestHTTPClientOptions(t *testing.T) {
+	given := &http.Client{Timeout: time.Minute, Transport: fakeTransport{"given"}}
+	proxy := fakeTransport{"proxy"}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		timeout   time.Duration
+		transport http.RoundTripper
+	}{
+		{"defaults", nil, defaultTimeout, nil},
+		{"client", []Option{WithHTTPClient(given)}, time.Minute, given.Transport},
+		{"client with timeout", []Option{WithHTTPClient(given), WithTimeout(time.Second)}, time.Second, given.Transport},
+		{"client with transport", []Option{WithTransport(proxy), WithHTTPClient(given)}, time.Minute, proxy},
+		{"timeout and transport", []Option{WithTimeout(time.Second), WithTransport(proxy)}, time.Second, proxy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newClientOptions(tt.opts).httpClient()
+			if client == given || client == http.DefaultClient {
+				t.Fatal("httpClient() shares the given client, want a copy")
+			}
+			if client.Timeout != tt.timeout || client.Transport != tt.transport {
+				t.Errorf("httpClient() has timeout %s and transport %v, want %s and %v", client.Timeout, client.Transport, tt.timeout, tt.transport)
+			}
+		})
+	}
+
+	if given.Timeout != time.Minute || given.Transport != (fakeTransport{"given"}) {
+		t.Errorf("the options modified the given client to %+v", given)
+	}
+}
+
+func TestHTTPClientIsolation(t *testing.T) {
+	shared := &http.Client{Timeout: time.Minute}
+	oauth := NewOAuth("client-id", "client-secret", true, WithHTTPClient(shared))
+	basic := NewBasicAuth("merchant-id", "api-key", true, WithHTTPClient(shared))
+	other := NewBasicAuth("merchant-id", "api-key", true)
+	defaultTransport := http.DefaultClient.Transport
+
+	oauth.Client.Transport = fakeTransport{"changed"}
+	oauth.Client.Timeout = time.Second
+
+	if basic.Client.Transport != nil || basic.Client.Timeout != time.Minute {
+		t.Errorf("changing a client changed another client sharing its http.Client to %+v", basic.Client)
+	}
+	if other.Client.Transport != nil || other.Client.Timeout != defaultTimeout {
+		t.Errorf("changing a client changed another client to %+v", other.Client)
+	}
+	if shared.Transport != nil || shared.Timeout != time.Minute {
+		t.Errorf("changing a client changed the given http.Client to %+v", shared)
+	}
+	if http.DefaultClient.Transport != defaultTransport {
+		t.Error("changing a client changed http.DefaultClient")
+	}
+
+	// Changing the given client afterwards does not affect the clients either.
+	shared.Timeout = time.Hour
+	if basic.Client.Timeout != time.Minute {
+		t.Errorf("changing the given http.Client changed the client to %+v", basic.Client)
+	}
+}
+
+func TestRegistryClientsAreIsolated(t *testing.T) {
+	r := NewRegistry(nil)
+	a := r.Set("a", Config{ClientID: "a", ClientSecret: "secret", MerchantID: "a", APIKey: "key"})
+	b := r.Set("b", Config{ClientID: "b", ClientSecret: "secret", MerchantID: "b", APIKey: "key"})
+
+	a.Basic.Client.Transport = fakeTransport{"changed"}
+	if b.Basic.Client.Transport != nil || a.OAuth.Client.Transport != nil || r.Client.Transport != nil {
+		t.Error("changing the client of a tenant changed the others")
+	}
+}
@@ -0,0 +1,121 @@
+package vivawallet
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OrderState is the state of an order payment.
+type OrderState int
+
+// States of the order payments.
+const (
+	OrderPending  OrderState = 0
+	OrderExpired  OrderState = 1
+	OrderCanceled OrderState = 2
+	OrderPaid     OrderState = 3
+)
+
+func (s OrderState) String() string {
+	switch s {
+	case OrderPending:
+		return "Pending"
+	case OrderExpired:
+		return "Expired"
+	case OrderCanceled:
+		return "Canceled"
+	case OrderPaid:
+		return "Paid"
+	}
+	return fmt.Sprintf("OrderState(%d)", int(s))
+}
+
+// State returns the state of the order payment.
+func (r GetOrderPaymentResponse) State() OrderState {
+	return OrderState(r.StateID)
+}
+
+// PaymentMethodFee is the fee charged to the customer for paying with a payment method.
+// Fee is in cents.
+type PaymentMethodFee struct {
+	PaymentMethodID string `json:"paymentMethodId"`
+	Fee             int64  `json:"fee"`
+}
+
+// NBGLoyalty redeems the loyalty points of the customers of the National Bank of Greece.
+type NBGLoyalty struct {
+	IsNBGLoyalty              bool  `json:"isNbgLoyalty"`
+	NBGLoyaltyTransactionType int   `json:"nbgLoyaltyTransactionType,omitempty"`
+	IsRedemption              bool  `json:"isRedemption,omitempty"`
+	RedeemedAmount            int64 `json:"redeemedAmount,omitempty"`
+}
+
+// KlarnaOrderOptions describes the order to Klarna, which requires the addresses of the
+// customer and the lines of the order.
+type KlarnaOrderOptions struct {
+	Attachment      *KlarnaAttachment `json:"attachment,omitempty"`
+	BillingAddress  *KlarnaAddress    `json:"billingAddress,omitempty"`
+	ShippingAddress *KlarnaAddress    `json:"shippingAddress,omitempty"`
+	OrderLines      []KlarnaOrderLine `json:"orderLines,omitempty"`
+}
+
+// KlarnaAttachment holds extra merchant data, e.g. the purchase history of the customer,
+// as a json string.
+type KlarnaAttachment struct {
+	Body        string `json:"body"`
+	ContentType string `json:"content_type"`
+}
+
+type KlarnaAddress struct {
+	City           string `json:"city,omitempty"`
+	Country        string `json:"country,omitempty"`
+	Email          string `json:"email,omitempty"`
+	FamilyName     string `json:"family_name,omitempty"`
+	GivenName      string `json:"given_name,omitempty"`
+	Phone          string `json:"phone,omitempty"`
+	PostalCode     string `json:"postal_code,omitempty"`
+	Region         string `json:"region,omitempty"`
+	StreetAddress  string `json:"street_address,omitempty"`
+	StreetAddress2 string `json:"street_address2,omitempty"`
+	Title          string `json:"title,omitempty"`
+}
+
+// KlarnaOrderLine is a line of a Klarna order. Amounts are in cents and TaxRate is in
+// hundredths of a percent, e.g. 2400 for 24%.
+type KlarnaOrderLine struct {
+	Reference           string `json:"reference,omitempty"`
+	Type                string `json:"type,omitempty"`
+	Name                string `json:"name"`
+	Quantity            int    `json:"quantity"`
+	QuantityUnit        string `json:"quantity_unit,omitempty"`
+	UnitPrice           int64  `json:"unit_price"`
+	TaxRate             int    `json:"tax_rate"`
+	TotalAmount         int64  `json:"total_amount"`
+	TotalDiscountAmount int64  `json:"total_discount_amount,omitempty"`
+	TotalTaxAmount      int64  `json:"total_tax_amount"`
+	MerchantData        string `json:"merchant_data,omitempty"`
+	ImageURL            string `json:"image_url,omitempty"`
+	ProductURL          string `json:"product_url,omitempty"`
+}
+
+// MarshalJSON encodes the order along with its Extra options.
+func (o CheckoutOrder) MarshalJSON() ([]byte, error) {
+	type order CheckoutOrder
+	data, err := json.Marshal(order(o))
+	if err != nil || len(o.Extra) == 0 {
+		return data, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range o.Extra {
+		field, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse option %s %s", k, err)
+		}
+		fields[k] = field
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,80 @@
+package vivawallet
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCheckoutOrderMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		order CheckoutOrder
+		want  string
+	}{
+		{
+			name:  "without extra",
+			order: CheckoutOrder{Amount: 1000, SourceCode: "Default"},
+			want:  `{"amount":1000,"customer":{},"sourceCode":"Default"}`,
+		},
+		{
+			name: "extra options",
+			order: CheckoutOrder{Amount: 1000, Extra: map[string]interface{}{
+				"newOption": true,
+				"nested":    map[string]int{"a": 1},
+				"empty":     nil,
+			}},
+			want: `{"amount":1000,"customer":{},"empty":null,"nested":{"a":1},"newOption":true}`,
+		},
+		{
+			name: "extra overrides typed fields",
+			order: CheckoutOrder{Amount: 1000, SourceCode: "Default", Extra: map[string]interface{}{
+				"amount":     2000,
+				"sourceCode": "1234",
+				"customer":   map[string]string{"email": "user@example.com"},
+			}},
+			want: `{"amount":2000,"customer":{"email":"user@example.com"},"sourceCode":"1234"}`,
+		},
+		{
+			name: "extra sets omitted fields",
+			order: CheckoutOrder{Amount: 1000, Extra: map[string]interface{}{
+				"preauth": true,
+				"tags":    []string{"a"},
+			}},
+			want: `{"amount":1000,"customer":{},"preauth":true,"tags":["a"]}`,
+		},
+		{
+			name:  "large numbers are kept",
+			order: CheckoutOrder{Amount: 1272214778972604, Extra: map[string]interface{}{"orderCode": int64(1272214778972604)}},
+			want:  `{"amount":1272214778972604,"customer":{},"orderCode":1272214778972604}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", data, tt.want)
+			}
+
+			// The order is encoded the same way when it is a field of a request.
+			data, err = json.Marshal(struct {
+				Order *CheckoutOrder `json:"order"`
+			}{&tt.order})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := `{"order":` + tt.want + `}`; string(data) != want {
+				t.Errorf("Marshal() of a pointer = %s, want %s", data, want)
+			}
+		})
+	}
+}
+
+func TestCheckoutOrderMarshalJSONInvalidExtra(t *testing.T) {
+	order := CheckoutOrder{Amount: 1000, Extra: map[string]interface{}{"callback": func() {}}}
+	if _, err := json.Marshal(order); err == nil {
+		t.Error("Marshal() succeeded, want an error for the callback option")
+	}
+}
@@ -1,63 +1,95 @@
 package vivawallet
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"time"
 )
 
+// CheckoutOrder is the request of Create. Viva options the SDK does not know yet can be
+// set with Extra.
 type CheckoutOrder struct {
-	Amount               int64  `json:"amount"`
-	CustomerTransactions string `json:"customerTrns,omitempty"`
-	Customer             struct {
-		Email       string `json:"email,omitempty"`
-		FullName    string `json:"fullName,omitempty"`
-		Phone       string `json:"phone,omitempty"`
-		CountryCode string `json:"countryCode,omitempty"`
-		RequestLang string `json:"requestLang,omitempty"`
-	} `json:"customer,omitempty"`
+	Amount               int64    `json:"amount"`
+	CustomerTransactions string   `json:"customerTrns,omitempty"`
+	Customer             Customer `json:"customer,omitempty"`
 	PaymentTimeout       int      `json:"paymentTimeout,omitempty"`
 	PreAuth              bool     `json:"preauth,omitempty"`
 	AllowRecurring       bool     `json:"allowRecurring,omitempty"`
 	MaxInstallments      int      `json:"maxInstallments,omitempty"`
+	ForceMaxInstallments bool     `json:"forceMaxInstallments,omitempty"`
 	PaymentNotification  bool     `json:"paymentNotification,omitempty"`
 	TipAmount            int64    `json:"tipAmount,omitempty"`
+	AllowTipAdjustment   bool     `json:"allowTipAdjustment,omitempty"`
 	DisableExactAmount   bool     `json:"disableExactAmount,omitempty"`
 	DisableCash          bool     `json:"disableCash,omitempty"`
 	DisableWallet        bool     `json:"disableWallet,omitempty"`
 	SourceCode           string   `json:"sourceCode,omitempty"`
 	MerchantTransactions string   `json:"merchantTrns,omitempty"`
-	Tags                 []string `json:"tags,omitempty"`
-	CardTokens           []string `json:"cardTokens,omitempty"`
+	// DynamicDescriptor replaces the name of the merchant on the bank statement of the
+	// customer.
+	DynamicDescriptor   string              `json:"dynamicDescriptor,omitempty"`
+	CurrencyCode        int                 `json:"currencyCode,omitempty"`
+	Tags                []string            `json:"tags,omitempty"`
+	CardTokens          []string            `json:"cardTokens,omitempty"`
+	PaymentMethodFees   []PaymentMethodFee  `json:"paymentMethodFees,omitempty"`
+	IsCardVerification  bool                `json:"isCardVerification,omitempty"`
+	CardAcquisitionOnly bool                `json:"cardAcquisitionOnly,omitempty"`
+	StateID             OrderState          `json:"stateId,omitempty"`
+	NBGLoyalty          *NBGLoyalty         `json:"nbgLoyalty,omitempty"`
+	KlarnaOrderOptions  *KlarnaOrderOptions `json:"klarnaOrderOptions,omitempty"`
+	// Extra holds the options the SDK does not know yet. They are sent along with the
+	// other fields and take precedence over them.
+	Extra map[string]interface{} `json:"-"`
 }
 
 type CheckoutOrderResponse struct {
 	OrderCode int64 `json:"orderCode"`
 }
 
-// CreateOrderPayment creates a new order payment and returns the `orderCode`.
+// OrdersService handles the order payment endpoints.
+type OrdersService service
+
+// Create creates a new order payment and returns the `orderCode`. See
+// WithIdempotencyKey to avoid creating the order twice when retrying.
 // Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Payments/paths/~1checkout~1v2~1orders/post
-func (c OAuthClient) CreateOrderPayment(payload CheckoutOrder) (*CheckoutOrderResponse, error) {
-	// Check if auth expired and if so authenticate again
-	if c.HasAuthExpired() {
-		_, authErr := c.Authenticate()
-		return nil, fmt.Errorf("authentication error %s", authErr)
+func (s *OrdersService) Create(ctx context.Context, payload CheckoutOrder) (*CheckoutOrderResponse, error) {
+	return s.create(ctx, "CreateOrderPayment", payload)
+}
+
+// create creates an order payment on behalf of the operation op.
+func (s *OrdersService) create(ctx context.Context, op string, payload CheckoutOrder) (*CheckoutOrderResponse, error) {
+	ctx = withOperation(ctx, op)
+	if err := payload.Validate(); err != nil {
+		return nil, err
 	}
 
-	uri := checkoutOrderUri(c.Config)
-	data, err := json.Marshal(payload)
+	c, err := service(*s).oauthClient(ctx, ScopeRedirectCheckout)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse order %s", err)
+		return nil, err
 	}
 
-	response := &CheckoutOrderResponse{}
-	reqErr := c.Post(uri, bytes.NewReader(data), &response)
-	if reqErr != nil {
-		return nil, reqErr
+	uri := checkoutOrderUri(c.Config)
+	body, err := jsonBody(payload, "order")
+	if err != nil {
+		return nil, err
 	}
 
-	return response, nil
+	return idempotent(ctx, c.Idempotency, c.Config.ClientID, op, func(ctx context.Context) (*CheckoutOrderResponse, error) {
+		response := &CheckoutOrderResponse{}
+		reqErr := c.do(ctx, "POST", uri, body, response)
+		if reqErr != nil {
+			return nil, reqErr
+		}
+
+		return response, nil
+	}, nil)
+}
+
+// CreateOrderPayment creates a new order payment and returns the `orderCode`.
+//
+// Deprecated: use API.Orders.Create instead.
+func (c OAuthClient) CreateOrderPayment(payload CheckoutOrder) (*CheckoutOrderResponse, error) {
+	return NewAPI(&c, nil).Orders.Create(context.Background(), payload)
 }
 
 func checkoutOrderUri(c Config) string {
@@ -71,21 +103,33 @@ type UpdateOrderPayment struct {
 	IsCancelled      bool   `json:"isCancelled,omitempty"`
 }
 
-// UpdareOrderPayment updates a new order payment.
+// Update updates an existing order payment.
 // Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Payments-(Deprecated)/paths/~1api~1orders~1{orderCode}/patch
-func (c BasicAuthClient) UpdateOrderPayment(orderCode int64, payload UpdateOrderPayment) error {
-	uri := updateOrderUri(c.Config, orderCode)
-	data, err := json.Marshal(payload)
+func (s *OrdersService) Update(ctx context.Context, orderCode int64, payload UpdateOrderPayment) error {
+	ctx = withOperation(ctx, "UpdateOrderPayment", Attribute{Key: AttrOrderCode, Value: orderCode})
+	if err := payload.Validate(); err != nil {
+		return err
+	}
+
+	c, err := service(*s).basicClient()
 	if err != nil {
-		return fmt.Errorf("failed to parse order %s", err)
+		return err
 	}
 
-	reqErr := c.Patch(uri, bytes.NewReader(data))
-	if reqErr != nil {
-		return reqErr
+	uri := updateOrderUri(c.Config, orderCode)
+	body, err := jsonBody(payload, "order")
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return c.do(ctx, "PATCH", uri, body, nil)
+}
+
+// UpdateOrderPayment updates an existing order payment.
+//
+// Deprecated: use API.Orders.Update instead.
+func (c BasicAuthClient) UpdateOrderPayment(orderCode int64, payload UpdateOrderPayment) error {
+	return NewAPI(nil, &c).Orders.Update(context.Background(), orderCode, payload)
 }
 
 func updateOrderUri(c Config, orderCode int64) string {
@@ -106,19 +150,32 @@ type GetOrderPaymentResponse struct {
 	StateID         int      `json:"StateId"`
 }
 
-// GetOrderPayment retrieves an order payment
-// https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Payments-(Deprecated)/paths/~1api~1orders~1{orderCode}/get
-func (c BasicAuthClient) GetOrderPayment(orderCode int64) (*GetOrderPaymentResponse, error) {
+// Get retrieves an order payment.
+// Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Payments-(Deprecated)/paths/~1api~1orders~1{orderCode}/get
+func (s *OrdersService) Get(ctx context.Context, orderCode int64) (*GetOrderPaymentResponse, error) {
+	ctx = withOperation(ctx, "GetOrderPayment", Attribute{Key: AttrOrderCode, Value: orderCode})
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return nil, err
+	}
+
 	uri := getOrderPaymentUri(c.Config, orderCode)
 
 	op := &GetOrderPaymentResponse{}
-	reqErr := c.Get(uri, op)
+	reqErr := c.do(ctx, "GET", uri, nil, op)
 	if reqErr != nil {
 		return nil, reqErr
 	}
 	return op, nil
 }
 
+// GetOrderPayment retrieves an order payment.
+//
+// Deprecated: use API.Orders.Get instead.
+func (c BasicAuthClient) GetOrderPayment(orderCode int64) (*GetOrderPaymentResponse, error) {
+	return NewAPI(nil, &c).Orders.Get(context.Background(), orderCode)
+}
+
 func getOrderPaymentUri(c Config, orderCode int64) string {
 	return fmt.Sprintf("%s/api/orders/%d", AppUri(c), orderCode)
 }
@@ -133,19 +190,32 @@ type CancelOrderPayment struct {
 	Success       bool      `json:"Success"`
 }
 
-// CancelOrderPayment cancels an existing order payment
+// Cancel cancels an existing order payment.
 // Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Payments-(Deprecated)/paths/~1api~1orders~1{orderCode}/delete
-func (c BasicAuthClient) CancelOrderPayment(orderCode int64) (*CancelOrderPayment, error) {
+func (s *OrdersService) Cancel(ctx context.Context, orderCode int64) (*CancelOrderPayment, error) {
+	ctx = withOperation(ctx, "CancelOrderPayment", Attribute{Key: AttrOrderCode, Value: orderCode})
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return nil, err
+	}
+
 	uri := deleteOrderPaymentUri(c.Config, orderCode)
 
 	result := &CancelOrderPayment{}
-	reqErr := c.Delete(uri, nil, result)
+	reqErr := c.do(ctx, "DELETE", uri, nil, result)
 	if reqErr != nil {
 		return nil, reqErr
 	}
 	return result, nil
 }
 
+// CancelOrderPayment cancels an existing order payment.
+//
+// Deprecated: use API.Orders.Cancel instead.
+func (c BasicAuthClient) CancelOrderPayment(orderCode int64) (*CancelOrderPayment, error) {
+	return NewAPI(nil, &c).Orders.Cancel(context.Background(), orderCode)
+}
+
 func deleteOrderPaymentUri(c Config, orderCode int64) string {
 	return fmt.Sprintf("%s/api/orders/%d", AppUri(c), orderCode)
 }
@@ -0,0 +1,258 @@
+package vivawallet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when the rate limiter rejects a request.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// LimitMode is how the rate limiter handles a request when no token is available.
+type LimitMode int
+
+const (
+	// LimitWait waits until a token is available or the context is done. Requests that
+	// cannot get a token before the deadline of their context fail right away.
+	LimitWait LimitMode = iota
+	// LimitQueue waits like LimitWait but fails when MaxQueue requests are already
+	// waiting. A MaxQueue of 0 leaves the queue unbounded.
+	LimitQueue
+	// LimitFailFast fails when no token is available.
+	LimitFailFast
+)
+
+// RateLimiter is a token bucket limiting the requests of one or more clients. When
+// PerCredential is set every merchant or OAuth client gets its own bucket, otherwise all
+// the clients sharing the limiter share the same one.
+//
+// Responses with status 429 halve the rate of the bucket and pause it for the duration
+// of their Retry-After header, while successful responses bring the rate gradually back
+// to Rate. Throttled requests are sent again up to RetryThrottled times, unless the
+// limiter fails fast or is disabled with a Rate of 0, since nothing would then hold the
+// retries back.
+type RateLimiter struct {
+	Rate           float64
+	Burst          int
+	Mode           LimitMode
+	MaxQueue       int
+	PerCredential  bool
+	RetryThrottled int
+
+	lock    sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewRateLimiter creates a limiter allowing rate requests per second, with bursts of up
+// to burst requests.
+func NewRateLimiter(rate float64, burst int, mode LimitMode) *RateLimiter {
+	return &RateLimiter{
+		Rate:  rate,
+		Burst: burst,
+		Mode:  mode,
+	}
+}
+
+// RateLimitState describes a bucket of the rate limiter.
+type RateLimitState struct {
+	Key     string
+	Tokens  float64
+	Rate    float64
+	Waiting int
+}
+
+// RateLimitMetrics is implemented by the Metrics that also report the state of the rate
+// limiter.
+type RateLimitMetrics interface {
+	ObserveRateLimit(state RateLimitState)
+	ObserveRateLimited(key string)
+}
+
+type bucket struct {
+	rate        float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	waiting     int
+}
+
+// minRateDivisor bounds how much throttled responses slow a bucket down.
+const minRateDivisor = 16
+
+// throttleDelay is the pause after a throttled response without a Retry-After header.
+const throttleDelay = time.Second
+
+func (l *RateLimiter) bucket(key string, now time.Time) *bucket {
+	if l.buckets == nil {
+		l.buckets = map[string]*bucket{}
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{rate: l.Rate, tokens: float64(l.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > float64(l.Burst) {
+			b.tokens = float64(l.Burst)
+		}
+		b.last = now
+	}
+	return b
+}
+
+func (l *RateLimiter) key(credential string) string {
+	if l.PerCredential {
+		return credential
+	}
+	return ""
+}
+
+// wait takes a token for a request, waiting for it according to the mode of the
+// limiter.
+func (l *RateLimiter) wait(ctx context.Context, credential string, m Metrics) error {
+	if l == nil || l.Rate <= 0 {
+		return nil
+	}
+
+	key := l.key(credential)
+	now := time.Now()
+
+	l.lock.Lock()
+	b := l.bucket(key, now)
+	b.tokens--
+
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	if pause := b.pausedUntil.Sub(now); pause > delay {
+		delay = pause
+	}
+
+	if delay > 0 && !l.admit(ctx, b, now.Add(delay)) {
+		b.tokens++
+		state := b.state(key)
+		l.lock.Unlock()
+
+		observeRateLimit(m, state, true)
+		return ErrRateLimited
+	}
+
+	if delay > 0 {
+		b.waiting++
+	}
+	state := b.state(key)
+	l.lock.Unlock()
+
+	observeRateLimit(m, state, false)
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.lock.Lock()
+	b.waiting--
+	if err != nil {
+		b.tokens++
+	}
+	l.lock.Unlock()
+	return err
+}
+
+// admit reports whether a request getting its token at the given time may wait for it.
+func (l *RateLimiter) admit(ctx context.Context, b *bucket, at time.Time) bool {
+	switch l.Mode {
+	case LimitFailFast:
+		return false
+	case LimitQueue:
+		if l.MaxQueue > 0 && b.waiting >= l.MaxQueue {
+			return false
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(at) {
+		return false
+	}
+	return true
+}
+
+// update adapts the rate of the bucket to the response of a request.
+func (l *RateLimiter) update(credential string, resp *http.Response, m Metrics) {
+	if l == nil || l.Rate <= 0 {
+		return
+	}
+
+	key := l.key(credential)
+	now := time.Now()
+
+	l.lock.Lock()
+	b := l.bucket(key, now)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		b.rate /= 2
+		if min := l.Rate / minRateDivisor; b.rate < min {
+			b.rate = min
+		}
+		if b.tokens > 0 {
+			b.tokens = 0
+		}
+		b.pausedUntil = now.Add(retryAfter(resp.Header))
+	} else if b.rate < l.Rate {
+		b.rate += l.Rate / 10
+		if b.rate > l.Rate {
+			b.rate = l.Rate
+		}
+	}
+	state := b.state(key)
+	l.lock.Unlock()
+
+	observeRateLimit(m, state, false)
+}
+
+// retries returns how many times a throttled request may be sent again. The retries wait
+// on the bucket paused by the throttled response, so a disabled limiter does not retry.
+func (l *RateLimiter) retries() int {
+	if l == nil || l.Rate <= 0 || l.Mode == LimitFailFast {
+		return 0
+	}
+	return l.RetryThrottled
+}
+
+func (b *bucket) state(key string) RateLimitState {
+	return RateLimitState{Key: key, Tokens: b.tokens, Rate: b.rate, Waiting: b.waiting}
+}
+
+func observeRateLimit(m Metrics, state RateLimitState, limited bool) {
+	rm, ok := m.(RateLimitMetrics)
+	if !ok {
+		return
+	}
+
+	if limited {
+		rm.ObserveRateLimited(state.Key)
+	}
+	rm.ObserveRateLimit(state)
+}
+
+// retryAfter reads the delay of a Retry-After header given in seconds.
+func retryAfter(header http.Header) time.Duration {
+	if s, err := strconv.Atoi(header.Get("Retry-After")); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	return throttleDelay
+}
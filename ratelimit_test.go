@@ -0,0 +1,189 @@
+package vivawallet
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdmitQueue(t *testing.T) {
+	ctx := context.Background()
+	at := time.Now().Add(time.Second)
+
+	tests := []struct {
+		name     string
+		maxQueue int
+		waiting  int
+		want     bool
+	}{
+		{"unbounded", 0, 100, true},
+		{"below the bound", 2, 1, true},
+		{"at the bound", 2, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &RateLimiter{Rate: 1, Burst: 1, Mode: LimitQueue, MaxQueue: tt.maxQueue}
+			if got := l.admit(ctx, &bucket{waiting: tt.waiting}, at); got != tt.want {
+				t.Errorf("admit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := NewRateLimiter(1, 2, LimitFailFast)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := l.wait(ctx, "", nil); err != nil {
+			t.Fatalf("request %d of the burst failed: %s", i, err)
+		}
+	}
+	if err := l.wait(ctx, "", nil); err != ErrRateLimited {
+		t.Errorf("request after the burst returned %v, want ErrRateLimited", err)
+	}
+}
+
+func TestRateLimiterWaitsForToken(t *testing.T) {
+	l := NewRateLimiter(50, 1, LimitWait)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.wait(ctx, "", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("3 requests at 50/s with a burst of 1 took %s, want about 40ms", elapsed)
+	}
+}
+
+func TestRateLimiterRejectsPastDeadline(t *testing.T) {
+	l := NewRateLimiter(1, 1, LimitWait)
+	if err := l.wait(context.Background(), "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	// Waiting until the deadline would return context.DeadlineExceeded instead.
+	if err := l.wait(ctx, "", nil); err != ErrRateLimited {
+		t.Errorf("wait() = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestRateLimiterPerCredential(t *testing.T) {
+	l := NewRateLimiter(1, 1, LimitFailFast)
+	l.PerCredential = true
+	ctx := context.Background()
+
+	for _, credential := range []string{"merchant-a", "merchant-b"} {
+		if err := l.wait(ctx, credential, nil); err != nil {
+			t.Errorf("first request of %s failed: %s", credential, err)
+		}
+	}
+	if err := l.wait(ctx, "merchant-a", nil); err != ErrRateLimited {
+		t.Errorf("second request of merchant-a returned %v, want ErrRateLimited", err)
+	}
+}
+
+func TestRateLimiterBacksOffOnThrottling(t *testing.T) {
+	l := NewRateLimiter(10, 5, LimitFailFast)
+	throttled := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"2"}}}
+	ok := &http.Response{StatusCode: http.StatusOK}
+
+	l.update("", throttled, nil)
+	b := l.buckets[""]
+	if b.rate != 5 || b.tokens > 0 {
+		t.Errorf("after a 429 the rate is %v with %v tokens, want 5 with none", b.rate, b.tokens)
+	}
+	if pause := time.Until(b.pausedUntil); pause < time.Second || pause > 2*time.Second {
+		t.Errorf("paused for %s, want the 2s of Retry-After", pause)
+	}
+	if err := l.wait(context.Background(), "", nil); err != ErrRateLimited {
+		t.Errorf("request during the pause returned %v, want ErrRateLimited", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		l.update("", throttled, nil)
+	}
+	if b.rate != 10.0/minRateDivisor {
+		t.Errorf("rate %v after repeated 429s, want the minimum %v", b.rate, 10.0/minRateDivisor)
+	}
+
+	for i := 0; i < 20; i++ {
+		l.update("", ok, nil)
+	}
+	if b.rate != 10 {
+		t.Errorf("rate %v after successful responses, want 10", b.rate)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"3":                             3 * time.Second,
+		"":                              throttleDelay,
+		"0":                             throttleDelay,
+		"Wed, 21 Oct 2026 07:28:00 GMT": throttleDelay,
+	}
+	for header, want := range tests {
+		if got := retryAfter(http.Header{"Retry-After": {header}}); got != want {
+			t.Errorf("retryAfter(%q) = %s, want %s", header, got, want)
+		}
+	}
+}
+
+func TestSendRetriesThrottledRequests(t *testing.T) {
+	var hits int32
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"WalletId":1}]`))
+	})
+	limiter := NewRateLimiter(100, 10, LimitWait)
+	limiter.RetryThrottled = 1
+	api.Wallets.basic.Limiter = limiter
+
+	wallets, err := api.Wallets.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wallets) != 1 || atomic.LoadInt32(&hits) != 2 {
+		t.Errorf("got %d wallets after %d requests, want 1 after 2", len(wallets), hits)
+	}
+}
+
+func TestSendReturnsThrottlingWithoutRetries(t *testing.T) {
+	failFast := NewRateLimiter(100, 10, LimitFailFast)
+	failFast.RetryThrottled = 3
+	disabled := NewRateLimiter(0, 0, LimitWait)
+	disabled.RetryThrottled = 3
+
+	tests := map[string]*RateLimiter{
+		"fail fast": failFast,
+		"disabled":  disabled,
+	}
+	for name, limiter := range tests {
+		t.Run(name, func(t *testing.T) {
+			var hits int32
+			api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&hits, 1)
+				w.WriteHeader(http.StatusTooManyRequests)
+			})
+			api.Wallets.basic.Limiter = limiter
+
+			if _, err := api.Wallets.List(context.Background()); err == nil {
+				t.Error("List() succeeded, want the throttling error")
+			}
+			if n := atomic.LoadInt32(&hits); n != 1 {
+				t.Errorf("sent %d requests, want 1", n)
+			}
+		})
+	}
+}
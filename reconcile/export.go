@@ -0,0 +1,66 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var csvHeader = []string{
+	"kind", "order_code", "merchant_reference", "expected_amount", "transaction_amount", "transaction_ids",
+}
+
+// WriteCSV writes the report with a line per entry. The transaction amount is the sum of
+// the transactions of the entry.
+func (r Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, e := range r.Entries {
+		var (
+			orderCode int64
+			reference string
+			expected  string
+			total     int64
+			ids       []string
+		)
+		if e.Expected != nil {
+			orderCode = e.Expected.OrderCode
+			reference = e.Expected.MerchantReference
+			expected = strconv.FormatInt(e.Expected.Amount, 10)
+		}
+		for _, t := range e.Transactions {
+			if orderCode == 0 {
+				orderCode = t.OrderCode
+			}
+			total += t.Amount
+			ids = append(ids, t.TransactionID)
+		}
+
+		record := []string{
+			string(e.Kind),
+			strconv.FormatInt(orderCode, 10),
+			reference,
+			expected,
+			strconv.FormatInt(total, 10),
+			strings.Join(ids, " "),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the report as json.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
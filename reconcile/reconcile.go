@@ -0,0 +1,199 @@
+// Package reconcile matches the payments expected by a merchant to the transactions of
+// Viva, and reports the discrepancies between them.
+//
+//	trxs, err := reconcile.Fetch(ctx, api, days)
+//	report := reconcile.Reconcile(expected, trxs)
+//	err = report.WriteCSV(os.Stdout)
+package reconcile
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+// Expected is a payment the merchant expects to have been charged. Amounts are in cents.
+type Expected struct {
+	OrderCode         int64  `json:"orderCode"`
+	Amount            int64  `json:"amount"`
+	MerchantReference string `json:"merchantReference,omitempty"`
+}
+
+// Transaction is a Viva transaction, whichever endpoint it was fetched from. Amounts are
+// in cents.
+type Transaction struct {
+	TransactionID     string    `json:"transactionId"`
+	ParentID          string    `json:"parentId,omitempty"`
+	OrderCode         int64     `json:"orderCode"`
+	Amount            int64     `json:"amount"`
+	StatusID          string    `json:"statusId"`
+	TransactionTypeID int       `json:"transactionTypeId"`
+	MerchantTrns      string    `json:"merchantTrns,omitempty"`
+	InsDate           time.Time `json:"insDate"`
+}
+
+// FromTransaction converts a transaction returned by the transaction search.
+func FromTransaction(t vivawallet.Transaction) Transaction {
+	return Transaction{
+		TransactionID:     t.TransactionID,
+		ParentID:          t.ParentID,
+		OrderCode:         t.Order.OrderCode,
+		Amount:            cents(t.Amount),
+		StatusID:          t.StatusID,
+		TransactionTypeID: t.TransactionType.TransactionTypeID,
+		MerchantTrns:      t.MerchantTrns,
+		InsDate:           t.InsDate,
+	}
+}
+
+// FromGetTransaction converts a transaction returned by GetTransaction, which does not
+// include its id.
+func FromGetTransaction(id string, t vivawallet.GetTransactionResponse) Transaction {
+	return Transaction{
+		TransactionID:     id,
+		OrderCode:         int64(t.OrderCode),
+		Amount:            cents(t.Amount),
+		StatusID:          t.StatusID,
+		TransactionTypeID: t.TransactionTypeID,
+		MerchantTrns:      t.MerchantTrns,
+		InsDate:           t.InsDate,
+	}
+}
+
+// Fetch searches the transactions of the given days.
+func Fetch(ctx context.Context, api *vivawallet.API, days []time.Time) ([]Transaction, error) {
+	var r []Transaction
+	for _, d := range days {
+		trxs, err := api.Transactions.List(ctx, vivawallet.ListTransactionsOptions{Date: d})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range trxs {
+			r = append(r, FromTransaction(t))
+		}
+	}
+	return r, nil
+}
+
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func (t Transaction) isCharge() bool {
+	typ := vivawallet.TransactionType(t.TransactionTypeID)
+	return t.StatusID == vivawallet.StatusFinished && !typ.IsRefund() && !typ.IsPreAuth()
+}
+
+func (t Transaction) isRefund() bool {
+	return t.StatusID == vivawallet.StatusFinished && vivawallet.TransactionType(t.TransactionTypeID).IsRefund()
+}
+
+// Kind is the outcome of the reconciliation of a payment or a transaction.
+type Kind string
+
+const (
+	KindMatch          Kind = "match"
+	KindMissingCapture Kind = "missing_capture"
+	KindAmountMismatch Kind = "amount_mismatch"
+	KindDuplicate      Kind = "duplicate_charge"
+	KindOrphanRefund   Kind = "orphan_refund"
+)
+
+// Entry is a line of the report. Expected is nil for orphan refunds.
+type Entry struct {
+	Kind         Kind          `json:"kind"`
+	Expected     *Expected     `json:"expected,omitempty"`
+	Transactions []Transaction `json:"transactions,omitempty"`
+}
+
+// Report is the result of a reconciliation, with an entry per expected payment and per
+// orphan refund.
+type Report struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Filter returns the entries of the given kind.
+func (r Report) Filter(kind Kind) []Entry {
+	var entries []Entry
+	for _, e := range r.Entries {
+		if e.Kind == kind {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Reconcile matches the expected payments to the transactions. A payment is matched to
+// the charges of its order code, or of its merchant reference when it has no order code.
+// Refunds are orphans when they neither belong to the order of an expected payment nor
+// refund one of its charges.
+func Reconcile(expected []Expected, trxs []Transaction) Report {
+	byOrder := map[int64][]Transaction{}
+	byReference := map[string][]Transaction{}
+	for _, t := range trxs {
+		if !t.isCharge() {
+			continue
+		}
+		if t.OrderCode != 0 {
+			byOrder[t.OrderCode] = append(byOrder[t.OrderCode], t)
+		}
+		if t.MerchantTrns != "" {
+			byReference[t.MerchantTrns] = append(byReference[t.MerchantTrns], t)
+		}
+	}
+
+	report := Report{}
+	orders := map[int64]bool{}
+	charges := map[string]bool{}
+	for i := range expected {
+		e := expected[i]
+
+		var matched []Transaction
+		if e.OrderCode != 0 {
+			matched = byOrder[e.OrderCode]
+			orders[e.OrderCode] = true
+		} else if e.MerchantReference != "" {
+			matched = byReference[e.MerchantReference]
+		}
+		for _, t := range matched {
+			charges[t.TransactionID] = true
+		}
+
+		report.Entries = append(report.Entries, Entry{
+			Kind:         classify(e, matched),
+			Expected:     &e,
+			Transactions: matched,
+		})
+	}
+
+	for _, t := range trxs {
+		if t.isRefund() && !orders[t.OrderCode] && !charges[t.ParentID] {
+			report.Entries = append(report.Entries, Entry{
+				Kind:         KindOrphanRefund,
+				Transactions: []Transaction{t},
+			})
+		}
+	}
+
+	for _, e := range report.Entries {
+		sort.Slice(e.Transactions, func(i, j int) bool {
+			return e.Transactions[i].InsDate.Before(e.Transactions[j].InsDate)
+		})
+	}
+	return report
+}
+
+func classify(e Expected, matched []Transaction) Kind {
+	switch {
+	case len(matched) == 0:
+		return KindMissingCapture
+	case len(matched) > 1:
+		return KindDuplicate
+	case matched[0].Amount != e.Amount:
+		return KindAmountMismatch
+	}
+	return KindMatch
+}
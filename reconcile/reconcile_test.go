@@ -0,0 +1,164 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+var day = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+func charge(id string, orderCode int64, amount int64, minutes int) Transaction {
+	return Transaction{
+		TransactionID:     id,
+		OrderCode:         orderCode,
+		Amount:            amount,
+		StatusID:          vivawallet.StatusFinished,
+		TransactionTypeID: int(vivawallet.TransactionTypeCardCharge),
+		InsDate:           day.Add(time.Duration(minutes) * time.Minute),
+	}
+}
+
+func refund(id string, parentID string, orderCode int64, amount int64) Transaction {
+	return Transaction{
+		TransactionID:     id,
+		ParentID:          parentID,
+		OrderCode:         orderCode,
+		Amount:            amount,
+		StatusID:          vivawallet.StatusFinished,
+		TransactionTypeID: int(vivawallet.TransactionTypeCardRefund),
+		InsDate:           day,
+	}
+}
+
+// ids returns the kind and the transaction ids of every entry of a report.
+func ids(r Report) [][]string {
+	var out [][]string
+	for _, e := range r.Entries {
+		line := []string{string(e.Kind)}
+		for _, t := range e.Transactions {
+			line = append(line, t.TransactionID)
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func TestReconcile(t *testing.T) {
+	byReference := charge("by-reference", 0, 300, 0)
+	byReference.MerchantTrns = "INV-7"
+
+	preAuth := charge("pre-auth", 4, 1000, 0)
+	preAuth.TransactionTypeID = int(vivawallet.TransactionTypeCardPreAuth)
+	failed := charge("failed", 4, 1000, 1)
+	failed.StatusID = "E"
+	pendingRefund := refund("pending-refund", "", 99, -100)
+	pendingRefund.StatusID = "E"
+
+	expected := []Expected{
+		{OrderCode: 1, Amount: 1000},
+		{OrderCode: 2, Amount: 1000},
+		{OrderCode: 3, Amount: 500},
+		{OrderCode: 4, Amount: 1000},
+		{MerchantReference: "INV-7", Amount: 300},
+	}
+	trxs := []Transaction{
+		charge("match", 1, 1000, 0),
+		charge("mismatch", 2, 900, 0),
+		charge("duplicate-2", 3, 500, 5),
+		charge("duplicate-1", 3, 500, 1),
+		preAuth,
+		failed,
+		byReference,
+		refund("order-refund", "", 1, -1000),
+		refund("parent-refund", "by-reference", 0, -300),
+		refund("orphan-refund", "unknown", 98, -100),
+		pendingRefund,
+	}
+
+	want := [][]string{
+		{"match", "match"},
+		{"amount_mismatch", "mismatch"},
+		{"duplicate_charge", "duplicate-1", "duplicate-2"},
+		{"missing_capture"},
+		{"match", "by-reference"},
+		{"orphan_refund", "orphan-refund"},
+	}
+	report := Reconcile(expected, trxs)
+	if got := ids(report); !reflect.DeepEqual(got, want) {
+		t.Errorf("Reconcile() = %q, want %q", got, want)
+	}
+	if n := len(report.Filter(KindMatch)); n != 2 {
+		t.Errorf("Filter(KindMatch) returned %d entries, want 2", n)
+	}
+}
+
+func TestReconcileIgnoresReferenceWithOrderCode(t *testing.T) {
+	// Payments with an order code are matched by it alone.
+	trx := charge("other-order", 2, 1000, 0)
+	trx.MerchantTrns = "INV-1"
+
+	report := Reconcile([]Expected{{OrderCode: 1, MerchantReference: "INV-1", Amount: 1000}}, []Transaction{trx})
+	if got, want := ids(report), [][]string{{"missing_capture"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reconcile() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	report := Reconcile(
+		[]Expected{{OrderCode: 3, Amount: 500}, {MerchantReference: "INV-7", Amount: 300}},
+		[]Transaction{charge("a", 3, 500, 0), charge("b", 3, 500, 1), refund("r", "x", 9, -100)},
+	)
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "kind,order_code,merchant_reference,expected_amount,transaction_amount,transaction_ids\n" +
+		"duplicate_charge,3,,500,1000,a b\n" +
+		"missing_capture,0,INV-7,300,0,\n" +
+		"orphan_refund,9,,,-100,r\n"
+	if buf.String() != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/transactions" || r.URL.Query().Get("date") != "2026-10-19" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"Transactions":[{
+			"TransactionId":"a","ParentId":"p","Amount":12.34,"StatusId":"F","MerchantTrns":"INV-1",
+			"InsDate":"2026-10-19T10:00:00Z","Order":{"OrderCode":7},"TransactionType":{"TransactionTypeId":5}
+		}]}`))
+	}))
+	defer srv.Close()
+
+	basic := vivawallet.NewBasicAuth("merchant-id", "api-key", true, vivawallet.WithBaseURL(srv.URL))
+	trxs, err := Fetch(context.Background(), vivawallet.NewAPI(nil, basic), []time.Time{day})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Transaction{{
+		TransactionID:     "a",
+		ParentID:          "p",
+		OrderCode:         7,
+		Amount:            1234,
+		StatusID:          "F",
+		TransactionTypeID: 5,
+		MerchantTrns:      "INV-1",
+		InsDate:           day.Add(10 * time.Hour),
+	}}
+	if !reflect.DeepEqual(trxs, want) {
+		t.Errorf("Fetch() = %+v, want %+v", trxs, want)
+	}
+}
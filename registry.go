@@ -0,0 +1,136 @@
+package vivawallet
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Tenant holds the clients of a merchant of a Registry.
+type Tenant struct {
+	ID     string
+	Config Config
+	OAuth  *OAuthClient
+	Basic  *BasicAuthClient
+	API    *API
+}
+
+// Registry holds the clients of several merchants, keyed by a tenant id of your choice.
+// All the clients get a copy of the same http.Client, sharing its transport hence its
+// connection pool, while each tenant keeps its own OAuth token.
+type Registry struct {
+	// Client is the http.Client copied by the clients of the tenants.
+	Client *http.Client
+	// Tokens, when set, stores the tokens of all the tenants, each under its own keys.
+	// Every tenant otherwise gets its own in-memory store.
+	Tokens TokenStore
+	// Options are applied to the clients of every tenant, after the shared
+	// http.Client.
+	Options []Option
+	// Configure, when set, is called with the clients of every tenant before they are
+	// used, e.g. to set their logger or metrics.
+	Configure func(t *Tenant)
+
+	lock    sync.RWMutex
+	tenants map[string]*Tenant
+}
+
+// NewRegistry creates an empty registry sharing the given http.Client, or a new one with
+// the default timeout when nil.
+func NewRegistry(client *http.Client) *Registry {
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	return &Registry{
+		Client:  client,
+		tenants: map[string]*Tenant{},
+	}
+}
+
+// Set creates the clients of a tenant, replacing the existing ones if any. Calls in
+// progress with the replaced clients complete with the previous credentials.
+func (r *Registry) Set(tenantID string, c Config) *Tenant {
+	opts := append([]Option{WithHTTPClient(r.Client)}, r.Options...)
+
+	oauth := NewOAuthFromConfig(c, opts...)
+	if r.Tokens != nil {
+		oauth.Tokens = tenantTokenStore{store: r.Tokens, tenantID: tenantID}
+	}
+
+	basic := NewBasicAuthFromConfig(c, opts...)
+
+	t := &Tenant{
+		ID:     tenantID,
+		Config: c,
+		OAuth:  oauth,
+		Basic:  basic,
+		API:    NewAPI(oauth, basic),
+	}
+	if r.Configure != nil {
+		r.Configure(t)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.tenants == nil {
+		r.tenants = map[string]*Tenant{}
+	}
+	r.tenants[tenantID] = t
+	return t
+}
+
+// Get returns the clients of a tenant.
+func (r *Registry) Get(tenantID string) (*Tenant, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	t, ok := r.tenants[tenantID]
+	return t, ok
+}
+
+// API returns the services of a tenant.
+func (r *Registry) API(tenantID string) (*API, error) {
+	t, ok := r.Get(tenantID)
+	if !ok {
+		return nil, fmt.Errorf("unknown tenant %s", tenantID)
+	}
+	return t.API, nil
+}
+
+// Remove removes the clients of a tenant.
+func (r *Registry) Remove(tenantID string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.tenants, tenantID)
+}
+
+// Tenants returns the ids of the tenants, sorted.
+func (r *Registry) Tenants() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	ids := make([]string, 0, len(r.tenants))
+	for id := range r.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// tenantTokenStore keeps the tokens of a tenant apart from those of the others in a
+// shared store.
+type tenantTokenStore struct {
+	store    TokenStore
+	tenantID string
+}
+
+func (s tenantTokenStore) Load(key string) (*Token, error) {
+	return s.store.Load(s.tenantID + "/" + key)
+}
+
+func (s tenantTokenStore) Store(key string, t Token) error {
+	return s.store.Store(s.tenantID+"/"+key, t)
+}
@@ -0,0 +1,164 @@
+package vivawallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// tenantServer issues a token per client id and answers the transactions with the
+// token they were requested with as the name of the customer, and the wallets with the merchant requesting them.
+func tenantServer(t *testing.T, tokens *int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/connect/token":
+			atomic.AddInt32(tokens, 1)
+			clientID, _, _ := r.BasicAuth()
+			_ = json.NewEncoder(w).Encode(TokenResponse{AccessToken: "token-" + clientID, ExpiresIn: 3600, TokenType: "Bearer"})
+		case strings.HasPrefix(r.URL.Path, "/checkout/v2/transactions/"):
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			_ = json.NewEncoder(w).Encode(GetTransactionResponse{FullName: token})
+		case r.URL.Path == "/api/wallets":
+			merchantID, _, _ := r.BasicAuth()
+			_, _ = fmt.Fprintf(w, `[{"Iban":%q}]`, merchantID)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func tenantConfig(id string) Config {
+	return Config{Demo: true, ClientID: "client-" + id, ClientSecret: "secret", MerchantID: "merchant-" + id, APIKey: "key"}
+}
+
+// credentials returns the token and the merchant the requests of a tenant are sent with.
+func credentials(t *testing.T, r *Registry, tenantID string) (string, string) {
+	t.Helper()
+	api, err := r.API(tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trx, err := api.Transactions.Get(context.Background(), "trx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallets, err := api.Wallets.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trx.FullName, wallets[0].IBAN
+}
+
+func TestRegistryTenantsKeepTheirTokens(t *testing.T) {
+	var tokens int32
+	srv := tenantServer(t, &tokens)
+
+	for name, store := range map[string]TokenStore{"memory stores": nil, "shared store": NewMemoryTokenStore()} {
+		t.Run(name, func(t *testing.T) {
+			atomic.StoreInt32(&tokens, 0)
+			r := NewRegistry(nil)
+			r.Options = []Option{WithBaseURL(srv.URL)}
+			r.Tokens = store
+			r.Set("a", tenantConfig("a"))
+			r.Set("b", tenantConfig("b"))
+
+			for i := 0; i < 2; i++ {
+				for _, id := range []string{"a", "b"} {
+					token, merchant := credentials(t, r, id)
+					if token != "token-client-"+id || merchant != "merchant-"+id {
+						t.Errorf("tenant %s sent token %s and merchant %s", id, token, merchant)
+					}
+				}
+			}
+			if n := atomic.LoadInt32(&tokens); n != 2 {
+				t.Errorf("requested %d tokens, want one per tenant", n)
+			}
+		})
+	}
+}
+
+func TestRegistryHotSwap(t *testing.T) {
+	var tokens int32
+	srv := tenantServer(t, &tokens)
+
+	r := NewRegistry(nil)
+	r.Options = []Option{WithBaseURL(srv.URL)}
+	r.Tokens = NewMemoryTokenStore()
+	old := r.Set("a", tenantConfig("a"))
+	if token, _ := credentials(t, r, "a"); token != "token-client-a" {
+		t.Fatalf("tenant a sent token %s", token)
+	}
+
+	r.Set("a", tenantConfig("rotated"))
+	token, merchant := credentials(t, r, "a")
+	if token != "token-client-rotated" || merchant != "merchant-rotated" {
+		t.Errorf("swapped tenant sent token %s and merchant %s, want the new credentials", token, merchant)
+	}
+
+	// The clients handed out before the swap keep their credentials.
+	trx, err := old.API.Transactions.Get(context.Background(), "trx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trx.FullName != "token-client-a" {
+		t.Errorf("replaced clients sent token %s, want their own", trx.FullName)
+	}
+
+	r.Remove("a")
+	if _, err := r.API("a"); err == nil {
+		t.Error("API() of a removed tenant succeeded")
+	}
+	if ids := r.Tenants(); len(ids) != 0 {
+		t.Errorf("Tenants() = %v after removing the tenant", ids)
+	}
+}
+
+func TestRegistryConcurrentAccess(t *testing.T) {
+	var tokens int32
+	srv := tenantServer(t, &tokens)
+
+	r := NewRegistry(nil)
+	r.Options = []Option{WithBaseURL(srv.URL)}
+	r.Tokens = NewMemoryTokenStore()
+	ids := []string{"a", "b", "c"}
+	for _, id := range ids {
+		r.Set(id, tenantConfig(id))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		id := ids[i%len(ids)]
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			r.Set(id, tenantConfig(id))
+			_ = r.Tenants()
+		}()
+		go func() {
+			defer wg.Done()
+			api, err := r.API(id)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			trx, err := api.Transactions.Get(context.Background(), "trx")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if trx.FullName != "token-client-"+id {
+				t.Errorf("tenant %s sent token %s", id, trx.FullName)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,298 @@
+package vivawallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReportsService handles the data services endpoints generating the sales and
+// settlement files.
+type ReportsService service
+
+// Types of report files.
+const (
+	ReportSales      = "sales"
+	ReportSettlement = "settlement"
+)
+
+// Status of report files.
+const (
+	ReportPending = "pending"
+	ReportReady   = "ready"
+	ReportFailed  = "failed"
+)
+
+// ReportRequest describes the file to generate. The dates are inclusive.
+type ReportRequest struct {
+	Type string
+	From time.Time
+	To   time.Time
+}
+
+type reportRequestBody struct {
+	Type     string `json:"fileType"`
+	FromDate string `json:"fromDate"`
+	ToDate   string `json:"toDate"`
+}
+
+type ReportFile struct {
+	FileID  string    `json:"fileId"`
+	Status  string    `json:"status"`
+	Created time.Time `json:"created"`
+}
+
+// ReportRow is a transaction of a sales or settlement file. The fields are named after
+// those of GetTransactionResponse, settlement files additionally fill the clearance and
+// commission fields.
+type ReportRow struct {
+	TransactionID       string    `csv:"transactionId"`
+	Email               string    `csv:"email"`
+	Amount              float64   `csv:"amount"`
+	OrderCode           int       `csv:"orderCode"`
+	StatusID            string    `csv:"statusId"`
+	FullName            string    `csv:"fullName"`
+	InsDate             time.Time `csv:"insDate"`
+	CardNumber          string    `csv:"cardNumber"`
+	CurrencyCode        string    `csv:"currencyCode"`
+	CustomerTrns        string    `csv:"customerTrns"`
+	MerchantTrns        string    `csv:"merchantTrns"`
+	SourceCode          string    `csv:"sourceCode"`
+	TransactionTypeID   int       `csv:"transactionTypeId"`
+	TotalInstallments   int       `csv:"totalInstallments"`
+	CurrentInstallment  int       `csv:"currentInstallment"`
+	CardCountryCode     string    `csv:"cardCountryCode"`
+	CardIssuingBank     string    `csv:"cardIssuingBank"`
+	CardUniqueReference string    `csv:"cardUniqueReference"`
+	CardTypeID          int       `csv:"cardTypeId"`
+	DigitalWalletID     int       `csv:"digitalWalletId"`
+	ClearanceDate       time.Time `csv:"clearanceDate"`
+	Commission          float64   `csv:"commission"`
+}
+
+// Request asks Viva to generate a file for the given date range.
+// Ref: https://developer.vivawallet.com/apis-for-payments/data-services/
+func (s *ReportsService) Request(ctx context.Context, r ReportRequest) (*ReportFile, error) {
+	ctx = withOperation(ctx, "RequestReport")
+	if r.Type != ReportSales && r.Type != ReportSettlement {
+		return nil, fmt.Errorf("unknown report type %s", r.Type)
+	}
+	if r.To.Before(r.From) {
+		return nil, errors.New("report range ends before it starts")
+	}
+
+	c, err := service(*s).oauthClient(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	payload := reportRequestBody{
+		Type:     r.Type,
+		FromDate: r.From.Format("2006-01-02"),
+		ToDate:   r.To.Format("2006-01-02"),
+	}
+	body, err := jsonBody(payload, "report request")
+	if err != nil {
+		return nil, err
+	}
+
+	f := &ReportFile{}
+	reqErr := c.do(ctx, "POST", getReportsUri(c.Config), body, f)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	return f, nil
+}
+
+// Get fetches the status of a file.
+func (s *ReportsService) Get(ctx context.Context, fileID string) (*ReportFile, error) {
+	ctx = withOperation(ctx, "GetReport")
+	c, err := service(*s).oauthClient(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	f := &ReportFile{}
+	reqErr := c.do(ctx, "GET", getReportUri(c.Config, fileID), nil, f)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	return f, nil
+}
+
+// defaultReportInterval is the polling interval of Wait when none is given.
+const defaultReportInterval = 5 * time.Second
+
+// Wait polls the status of a file every interval until it is ready, it failed or ctx
+// is done. An interval of 0 or less polls every 5 seconds.
+func (s *ReportsService) Wait(ctx context.Context, fileID string, interval time.Duration) (*ReportFile, error) {
+	if interval <= 0 {
+		interval = defaultReportInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		f, err := s.Get(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch f.Status {
+		case ReportReady:
+			return f, nil
+		case ReportFailed:
+			return nil, fmt.Errorf("report %s failed", fileID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Download downloads a ready file and parses its rows.
+func (s *ReportsService) Download(ctx context.Context, fileID string) ([]ReportRow, error) {
+	ctx = withOperation(ctx, "DownloadReport")
+	c, err := service(*s).oauthClient(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	req := newRequest(ctx, "GET", getReportContentUri(c.Config, fileID), nil)
+	c.setBearerToken(req)
+
+	resp, body, httpErr := send(c.Client, c.hooks(), req)
+	if httpErr != nil {
+		return nil, fmt.Errorf("failed to perform request %s", httpErr)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to perform request with status %d", resp.StatusCode)
+	}
+
+	return ParseReport(bytes.NewReader(body))
+}
+
+func getReportsUri(c Config) string {
+	return fmt.Sprintf("%s/dataservices/v1/files", ApiUri(c))
+}
+
+func getReportUri(c Config, fileID string) string {
+	return fmt.Sprintf("%s/dataservices/v1/files/%s", ApiUri(c), fileID)
+}
+
+func getReportContentUri(c Config, fileID string) string {
+	return fmt.Sprintf("%s/dataservices/v1/files/%s/content", ApiUri(c), fileID)
+}
+
+// ParseReport parses the rows of a sales or settlement file. Columns are matched to the
+// fields of ReportRow ignoring case, spaces and punctuation, unknown columns are
+// ignored.
+func ParseReport(r io.Reader) ([]ReportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report %s", err)
+	}
+
+	fields := reportFields()
+	columns := make([]int, len(header))
+	for i, h := range header {
+		f, ok := fields[normalizeColumn(h)]
+		if !ok {
+			f = -1
+		}
+		columns[i] = f
+	}
+
+	var rows []ReportRow
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse report %s", err)
+		}
+
+		row := ReportRow{}
+		v := reflect.ValueOf(&row).Elem()
+		for i, value := range record {
+			if i >= len(columns) || columns[i] < 0 || value == "" {
+				continue
+			}
+			if err := setField(v.Field(columns[i]), value); err != nil {
+				return nil, fmt.Errorf("failed to parse report line %d column %s %s", line, header[i], err)
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// reportFields maps the normalized column names to the index of the ReportRow fields.
+func reportFields() map[string]int {
+	fields := map[string]int{}
+	t := reflect.TypeOf(ReportRow{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fields[normalizeColumn(f.Name)] = i
+		fields[normalizeColumn(f.Tag.Get("csv"))] = i
+	}
+	return fields
+}
+
+func normalizeColumn(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, name)
+}
+
+var reportTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05", "2006-01-02"}
+
+func setField(f reflect.Value, value string) error {
+	switch f.Interface().(type) {
+	case string:
+		f.SetString(value)
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(n))
+	case float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case time.Time:
+		for _, layout := range reportTimeLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				f.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid date %s", value)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package vivawallet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseReport(t *testing.T) {
+	insDate := time.Date(2026, 10, 19, 10, 24, 18, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		csv  string
+		want []ReportRow
+		err  string
+	}{
+		{
+			name: "tag names",
+			csv: "transactionId,amount,orderCode,statusId,insDate,transactionTypeId\n" +
+				"trx-1,12.5,1272214778,F,2026-10-19T10:24:18Z,5\n",
+			want: []ReportRow{{TransactionID: "trx-1", Amount: 12.5, OrderCode: 1272214778, StatusID: "F", InsDate: insDate, TransactionTypeID: 5}},
+		},
+		{
+			name: "normalized column names",
+			csv: "Transaction ID,AMOUNT,Merchant_Trns,Clearance Date,Commission\n" +
+				"trx-1,10,INV-7,2026-10-20,0.15\n",
+			want: []ReportRow{{TransactionID: "trx-1", Amount: 10, MerchantTrns: "INV-7", ClearanceDate: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), Commission: 0.15}},
+		},
+		{
+			name: "date layouts",
+			csv:  "insDate\n2026-10-19T10:24:18.000\n2026-10-19 10:24:18\n2026-10-19T13:24:18+03:00\n",
+			want: []ReportRow{{InsDate: insDate}, {InsDate: insDate}, {InsDate: insDate.In(time.FixedZone("", 3*60*60))}},
+		},
+		{
+			name: "unknown and empty columns",
+			csv:  "transactionId,terminalId,amount\ntrx-1,123,\n",
+			want: []ReportRow{{TransactionID: "trx-1"}},
+		},
+		{
+			name: "short and long rows",
+			csv:  "transactionId,amount\ntrx-1\ntrx-2,1,extra\n",
+			want: []ReportRow{{TransactionID: "trx-1"}, {TransactionID: "trx-2", Amount: 1}},
+		},
+		{
+			name: "header only",
+			csv:  "transactionId,amount\n",
+		},
+		{
+			name: "empty",
+		},
+		{
+			name: "malformed amount",
+			csv:  "transactionId,amount\ntrx-1,12;5\n",
+			err:  "line 2 column amount",
+		},
+		{
+			name: "malformed date",
+			csv:  "transactionId,insDate\ntrx-1,19/10/2026\n",
+			err:  "invalid date 19/10/2026",
+		},
+		{
+			name: "malformed csv",
+			csv:  "transactionId,amount\n\"trx-1,1\n",
+			err:  "failed to parse report",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ParseReport(strings.NewReader(tt.csv))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("ParseReport() error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("ParseReport() =\n%+v\nwant\n%+v", rows, tt.want)
+			}
+		})
+	}
+}
+
+// reportServer serves a file which is ready after the given number of polls, or fails.
+func reportServer(t *testing.T, polls *int32, readyAfter int32, status string) *API {
+	t.Helper()
+	return newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dataservices/v1/files/f":
+			f := ReportFile{FileID: "f", Status: ReportPending}
+			if atomic.AddInt32(polls, 1) >= readyAfter {
+				f.Status = status
+			}
+			_ = json.NewEncoder(w).Encode(f)
+		case "/dataservices/v1/files/f/content":
+			_, _ = w.Write([]byte("transactionId,amount\ntrx-1,12.5\ntrx-2,-3\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestReportsWait(t *testing.T) {
+	var polls int32
+	api := reportServer(t, &polls, 3, ReportReady)
+
+	f, err := api.Reports.Wait(context.Background(), "f", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Status != ReportReady || atomic.LoadInt32(&polls) != 3 {
+		t.Errorf("Wait() = %+v after %d polls, want ready after 3", f, polls)
+	}
+}
+
+func TestReportsWaitFailed(t *testing.T) {
+	var polls int32
+	api := reportServer(t, &polls, 1, ReportFailed)
+
+	if _, err := api.Reports.Wait(context.Background(), "f", time.Millisecond); err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Errorf("Wait() error %v, want the report failed", err)
+	}
+}
+
+func TestReportsWaitContextDone(t *testing.T) {
+	var polls int32
+	api := reportServer(t, &polls, 1000, ReportReady)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	// The deadline may pass while polling or while sending a request.
+	if _, err := api.Reports.Wait(ctx, "f", time.Millisecond); err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("Wait() error %v, want context deadline exceeded", err)
+	}
+}
+
+func TestReportsWaitDefaultInterval(t *testing.T) {
+	var polls int32
+	api := reportServer(t, &polls, 1, ReportReady)
+
+	if _, err := api.Reports.Wait(context.Background(), "f", 0); err != nil {
+		t.Errorf("Wait() with no interval failed: %s", err)
+	}
+}
+
+func TestReportsDownload(t *testing.T) {
+	var polls int32
+	api := reportServer(t, &polls, 1, ReportReady)
+
+	rows, err := api.Reports.Download(context.Background(), "f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ReportRow{{TransactionID: "trx-1", Amount: 12.5}, {TransactionID: "trx-2", Amount: -3}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Download() = %+v, want %+v", rows, want)
+	}
+
+	if _, err := api.Reports.Download(context.Background(), "missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Download() of a missing file returned %v, want status 404", err)
+	}
+}
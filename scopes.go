@@ -0,0 +1,157 @@
+package vivawallet
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OAuth scopes of the Viva APIs.
+const (
+	ScopeRedirectCheckout      = "urn:viva:payments:core:api:redirectcheckout"
+	ScopeNativeCheckout        = "urn:viva:payments:core:api:nativecheckoutv2"
+	ScopeAcquiring             = "urn:viva:payments:core:api:acquiring"
+	ScopeAcquiringTransactions = "urn:viva:payments:core:api:acquiring:transactions"
+)
+
+// ScopeError is returned when an endpoint requires a scope the access token was not
+// granted.
+type ScopeError struct {
+	Required string
+	Granted  []string
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("access token lacks scope %s, granted scopes: %s", e.Required, strings.Join(e.Granted, " "))
+}
+
+// parseScopes splits the space separated scopes of a token response.
+func parseScopes(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// hasScope reports whether the required scope is covered by the granted ones. A scope
+// covers its sub-scopes, e.g. `acquiring` covers `acquiring:transactions`.
+func hasScope(granted []string, required string) bool {
+	for _, g := range granted {
+		if g == required || strings.HasPrefix(required, g+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// Scopes returns the scopes granted to the current token, or nil if they are unknown.
+func (c OAuthClient) Scopes() []string {
+	t, err := c.Tokens.Load(c.tokenKey())
+	if err != nil || t == nil {
+		return nil
+	}
+	return t.Scopes
+}
+
+// requireScope fails if the current token was granted scopes and none of them covers
+// the required one. Tokens with unknown scopes, and endpoints without a known scope, are
+// let through and left for Viva to reject.
+func (c OAuthClient) requireScope(required string) error {
+	granted := c.Scopes()
+	if required == "" || len(granted) == 0 || hasScope(granted, required) {
+		return nil
+	}
+	return &ScopeError{Required: required, Granted: granted}
+}
+
+// TokenClaims are the claims of the access token. The token is decoded without verifying
+// its signature, so they are meant for debugging and must not be trusted.
+type TokenClaims struct {
+	Issuer     string
+	Subject    string
+	ClientID   string
+	MerchantID string
+	Audience   []string
+	Scopes     []string
+	IssuedAt   time.Time
+	NotBefore  time.Time
+	ExpiresAt  time.Time
+	Raw        map[string]interface{}
+}
+
+// TokenClaims decodes the claims of the current access token.
+func (c OAuthClient) TokenClaims() (*TokenClaims, error) {
+	token := c.AuthToken()
+	if token == "" {
+		return nil, errors.New("no access token")
+	}
+	return ParseTokenClaims(token)
+}
+
+// ParseTokenClaims decodes the claims of a jwt access token without verifying it.
+func ParseTokenClaims(token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("access token is not a jwt")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode access token %s", err)
+	}
+
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse access token %s", err)
+	}
+
+	claims := &TokenClaims{
+		Issuer:    claimString(raw, "iss"),
+		Subject:   claimString(raw, "sub"),
+		ClientID:  claimString(raw, "client_id"),
+		Audience:  claimStrings(raw, "aud"),
+		Scopes:    claimStrings(raw, "scope"),
+		IssuedAt:  claimTime(raw, "iat"),
+		NotBefore: claimTime(raw, "nbf"),
+		ExpiresAt: claimTime(raw, "exp"),
+		Raw:       raw,
+	}
+	for _, k := range []string{"merchant_id", "merchantId", "MerchantId"} {
+		if v := claimString(raw, k); v != "" {
+			claims.MerchantID = v
+			break
+		}
+	}
+	return claims, nil
+}
+
+func claimString(raw map[string]interface{}, key string) string {
+	if v, ok := raw[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// claimStrings reads a claim that is either a list or a single, space separated string.
+func claimStrings(raw map[string]interface{}, key string) []string {
+	switch v := raw[key].(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var r []string
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				r = append(r, s)
+			}
+		}
+		return r
+	}
+	return nil
+}
+
+func claimTime(raw map[string]interface{}, key string) time.Time {
+	if v, ok := raw[key].(float64); ok {
+		return time.Unix(int64(v), 0)
+	}
+	return time.Time{}
+}
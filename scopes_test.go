@@ -0,0 +1,101 @@
+package vivawallet
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		granted  []string
+		required string
+		want     bool
+	}{
+		{[]string{ScopeAcquiring}, ScopeAcquiring, true},
+		{[]string{ScopeAcquiring}, ScopeAcquiringTransactions, true},
+		{[]string{ScopeAcquiringTransactions}, ScopeAcquiring, false},
+		{[]string{ScopeRedirectCheckout}, ScopeNativeCheckout, false},
+		{[]string{ScopeAcquiring + "s"}, ScopeAcquiring, false},
+		{nil, ScopeAcquiring, false},
+	}
+
+	for _, tt := range tests {
+		if got := hasScope(tt.granted, tt.required); got != tt.want {
+			t.Errorf("hasScope(%v, %s) = %t, want %t", tt.granted, tt.required, got, tt.want)
+		}
+	}
+}
+
+// jwt encodes the claims as an unsigned jwt.
+func jwt(claims string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(claims)) + ".signature"
+}
+
+func TestParseTokenClaims(t *testing.T) {
+	token := jwt(`{"iss":"https://demo-accounts.vivapayments.com","sub":"subject","client_id":"client-id",` +
+		`"merchantId":"merchant-id","aud":"core_api","scope":"urn:viva:payments:core:api:redirectcheckout urn:viva:payments:core:api:acquiring",` +
+		`"iat":1760865858,"nbf":1760865858,"exp":1760869458}`)
+
+	claims, err := ParseTokenClaims(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued := time.Unix(1760865858, 0)
+	want := TokenClaims{
+		Issuer:     "https://demo-accounts.vivapayments.com",
+		Subject:    "subject",
+		ClientID:   "client-id",
+		MerchantID: "merchant-id",
+		Audience:   []string{"core_api"},
+		Scopes:     []string{ScopeRedirectCheckout, ScopeAcquiring},
+		IssuedAt:   issued,
+		NotBefore:  issued,
+		ExpiresAt:  issued.Add(time.Hour),
+	}
+	claims.Raw = nil
+	if !reflect.DeepEqual(*claims, want) {
+		t.Errorf("ParseTokenClaims() =\n%+v\nwant\n%+v", *claims, want)
+	}
+}
+
+func TestParseTokenClaimsArrays(t *testing.T) {
+	tests := map[string]string{
+		"unpadded": jwt(`{"aud":["core_api","accounts"],"scope":["urn:viva:payments:core:api:nativecheckoutv2",1,"urn:viva:payments:core:api:acquiring"]}`),
+		"padded":   strings.Replace(jwt(`{"aud":["core_api","accounts"],"scope":["urn:viva:payments:core:api:nativecheckoutv2",1,"urn:viva:payments:core:api:acquiring"]}`), ".signature", "==.signature", 1),
+	}
+	for name, token := range tests {
+		claims, err := ParseTokenClaims(token)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if want := []string{ScopeNativeCheckout, ScopeAcquiring}; !reflect.DeepEqual(claims.Scopes, want) {
+			t.Errorf("%s: scopes %v, want %v", name, claims.Scopes, want)
+		}
+		if want := []string{"core_api", "accounts"}; !reflect.DeepEqual(claims.Audience, want) {
+			t.Errorf("%s: audience %v, want %v", name, claims.Audience, want)
+		}
+		if !claims.ExpiresAt.IsZero() || claims.MerchantID != "" {
+			t.Errorf("%s: missing claims read as %s and %q", name, claims.ExpiresAt, claims.MerchantID)
+		}
+	}
+}
+
+func TestParseTokenClaimsMalformed(t *testing.T) {
+	tests := map[string]string{
+		"opaque":        "c2VjcmV0",
+		"two parts":     "header.payload",
+		"four parts":    "a.b.c.d",
+		"bad base64":    "header.!!!.signature",
+		"bad json":      jwt(`{"scope":`),
+		"not an object": jwt(`["scope"]`),
+	}
+	for name, token := range tests {
+		if claims, err := ParseTokenClaims(token); err == nil {
+			t.Errorf("%s: ParseTokenClaims() = %+v, want an error", name, claims)
+		}
+	}
+}
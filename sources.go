@@ -0,0 +1,58 @@
+package vivawallet
+
+import (
+	"context"
+	"fmt"
+)
+
+// SourcesService handles the payment source endpoints.
+type SourcesService service
+
+type Source struct {
+	Name        string `json:"name"`
+	SourceCode  string `json:"sourceCode"`
+	Domain      string `json:"domain,omitempty"`
+	IsSecure    bool   `json:"isSecure,omitempty"`
+	PathFail    string `json:"pathFail,omitempty"`
+	PathSuccess string `json:"pathSuccess,omitempty"`
+}
+
+// Create creates a new payment source.
+// Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Payment-Sources/paths/~1api~1sources/post
+func (s *SourcesService) Create(ctx context.Context, payload Source) error {
+	ctx = withOperation(ctx, "CreateSource")
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return err
+	}
+
+	uri := getSourcesUri(c.Config)
+	body, err := jsonBody(payload, "source")
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, "POST", uri, body, nil)
+}
+
+// List fetches the payment sources of your account.
+func (s *SourcesService) List(ctx context.Context) ([]Source, error) {
+	ctx = withOperation(ctx, "ListSources")
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	uri := getSourcesUri(c.Config)
+
+	var r []Source
+	reqErr := c.do(ctx, "GET", uri, nil, &r)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	return r, nil
+}
+
+func getSourcesUri(c Config) string {
+	return fmt.Sprintf("%s/api/sources", AppUri(c))
+}
@@ -0,0 +1,136 @@
+package vivawallet
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+)
+
+type AccountTransaction struct {
+	TransactionID string    `json:"TransactionId"`
+	WalletID      int       `json:"WalletId"`
+	Amount        float64   `json:"Amount"`
+	Balance       float64   `json:"Balance"`
+	CurrencyCode  string    `json:"CurrencyCode"`
+	Description   string    `json:"Description"`
+	Reference     string    `json:"Reference"`
+	Date          time.Time `json:"Date"`
+	ValueDate     time.Time `json:"ValueDate"`
+}
+
+// Statement lists the account transactions of a wallet for a date range. Amounts are
+// signed, credits being positive.
+type Statement struct {
+	Wallet         Wallet
+	From           time.Time
+	To             time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	Transactions   []AccountTransaction
+}
+
+// Transactions fetches the account transactions of a wallet between two dates.
+func (s *WalletsService) Transactions(ctx context.Context, walletID int, from time.Time, to time.Time) ([]AccountTransaction, error) {
+	ctx = withOperation(ctx, "GetAccountTransactions")
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	uri := getAccountTransactionsUri(c.Config, walletID, from, to)
+
+	var r []AccountTransaction
+	reqErr := c.do(ctx, "GET", uri, nil, &r)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	return r, nil
+}
+
+// Statement builds the statement of a wallet from its account transactions, sorted by
+// date. The balances are derived from the balance after each transaction. When there is
+// none in the range, they are derived from the first transaction after it, or are the
+// current balance of the wallet when there is none either.
+func (s *WalletsService) Statement(ctx context.Context, w Wallet, from time.Time, to time.Time) (*Statement, error) {
+	trxs, err := s.Transactions(ctx, w.WalletID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	sortByDate(trxs)
+
+	st := &Statement{
+		Wallet:       w,
+		From:         from,
+		To:           to,
+		Transactions: trxs,
+	}
+	if len(trxs) > 0 {
+		first := trxs[0]
+		st.OpeningBalance = first.Balance - first.Amount
+		st.ClosingBalance = trxs[len(trxs)-1].Balance
+		return st, nil
+	}
+
+	balance, err := s.balanceAfter(ctx, w, to)
+	if err != nil {
+		return nil, err
+	}
+	st.OpeningBalance = balance
+	st.ClosingBalance = balance
+	return st, nil
+}
+
+// balanceAfter returns the balance of a wallet at the end of the given day, before the
+// transactions made since.
+func (s *WalletsService) balanceAfter(ctx context.Context, w Wallet, day time.Time) (float64, error) {
+	next := day.AddDate(0, 0, 1)
+	now := time.Now()
+	if next.After(now) {
+		return w.Amount, nil
+	}
+
+	later, err := s.Transactions(ctx, w.WalletID, next, now)
+	if err != nil {
+		return 0, err
+	}
+	if len(later) == 0 {
+		return w.Amount, nil
+	}
+	sortByDate(later)
+	return later[0].Balance - later[0].Amount, nil
+}
+
+func sortByDate(trxs []AccountTransaction) {
+	sort.SliceStable(trxs, func(i, j int) bool {
+		return trxs[i].Date.Before(trxs[j].Date)
+	})
+}
+
+// MT940 fetches the MT940 statement file Viva generates for a wallet.
+func (s *WalletsService) MT940(ctx context.Context, walletID int, from time.Time, to time.Time) ([]byte, error) {
+	ctx = withOperation(ctx, "GetMT940")
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	req := newRequest(ctx, "GET", getMT940Uri(c.Config, walletID, from, to), nil)
+	return c.performReq(req)
+}
+
+func dateRange(from time.Time, to time.Time) string {
+	params := url.Values{}
+	params.Set("dateFrom", from.Format("2006-01-02"))
+	params.Set("dateTo", to.Format("2006-01-02"))
+	return params.Encode()
+}
+
+func getAccountTransactionsUri(c Config, walletID int, from time.Time, to time.Time) string {
+	return fmt.Sprintf("%s/api/wallets/%d/transactions?%s", AppUri(c), walletID, dateRange(from, to))
+}
+
+func getMT940Uri(c Config, walletID int, from time.Time, to time.Time) string {
+	return fmt.Sprintf("%s/api/wallets/%d/statements/mt940?%s", AppUri(c), walletID, dateRange(from, to))
+}
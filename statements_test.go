@@ -0,0 +1,121 @@
+package vivawallet
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStatementSortsTransactions(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/wallets/1/transactions" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"TransactionId":"c","Amount":-30,"Balance":70,"Date":"2026-10-03T09:00:00Z"},
+			{"TransactionId":"a","Amount":100,"Balance":100,"Date":"2026-10-01T09:00:00Z"},
+			{"TransactionId":"b","Amount":0,"Balance":100,"Date":"2026-10-02T09:00:00Z"}
+		]`))
+	})
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	st, err := api.Wallets.Statement(context.Background(), Wallet{WalletID: 1, Amount: 70}, from, from.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids string
+	for _, trx := range st.Transactions {
+		ids += trx.TransactionID
+	}
+	if ids != "abc" {
+		t.Errorf("transactions in order %s, want abc", ids)
+	}
+	if st.OpeningBalance != 0 || st.ClosingBalance != 70 {
+		t.Errorf("balances %v and %v, want 0 and 70", st.OpeningBalance, st.ClosingBalance)
+	}
+}
+
+func TestStatementWithoutTransactions(t *testing.T) {
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 6)
+
+	tests := []struct {
+		name    string
+		later   string
+		balance float64
+		queries []string
+	}{
+		{
+			name:    "later transactions",
+			later:   `[{"Amount":-30,"Balance":120,"Date":"2026-09-20T09:00:00Z"},{"Amount":50,"Balance":150,"Date":"2026-09-10T09:00:00Z"}]`,
+			balance: 100,
+			queries: []string{"dateFrom=2026-09-01&dateTo=2026-09-07", "dateFrom=2026-09-08&dateTo=" + time.Now().Format("2006-01-02")},
+		},
+		{
+			name:    "no later transactions",
+			later:   `[]`,
+			balance: 120,
+			queries: []string{"dateFrom=2026-09-01&dateTo=2026-09-07", "dateFrom=2026-09-08&dateTo=" + time.Now().Format("2006-01-02")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries []string
+			api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				queries = append(queries, r.URL.RawQuery)
+				if r.URL.Query().Get("dateFrom") == "2026-09-01" {
+					_, _ = w.Write([]byte(`[]`))
+					return
+				}
+				_, _ = w.Write([]byte(tt.later))
+			})
+
+			st, err := api.Wallets.Statement(context.Background(), Wallet{WalletID: 1, Amount: 120}, from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.OpeningBalance != tt.balance || st.ClosingBalance != tt.balance {
+				t.Errorf("balances %v and %v, want %v", st.OpeningBalance, st.ClosingBalance, tt.balance)
+			}
+			if !reflect.DeepEqual(queries, tt.queries) {
+				t.Errorf("queried %q, want %q", queries, tt.queries)
+			}
+		})
+	}
+}
+
+func TestStatementOfTodayWithoutTransactions(t *testing.T) {
+	var requests int
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	now := time.Now()
+	st, err := api.Wallets.Statement(context.Background(), Wallet{WalletID: 1, Amount: 120}, now, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.OpeningBalance != 120 || st.ClosingBalance != 120 || requests != 1 {
+		t.Errorf("balances %v and %v after %d requests, want the current balance after 1", st.OpeningBalance, st.ClosingBalance, requests)
+	}
+}
+
+func TestStatementLaterTransactionsError(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("dateFrom") == "2026-09-01" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := api.Wallets.Statement(context.Background(), Wallet{WalletID: 1, Amount: 120}, from, from); err == nil {
+		t.Error("Statement() succeeded without the balance of the range")
+	}
+}
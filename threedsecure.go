@@ -0,0 +1,119 @@
+package vivawallet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ThreeDSecureStatus is the outcome of the 3-D Secure authentication of a card payment,
+// as reported by the ThreeDSecureStatusID of the transactions.
+type ThreeDSecureStatus int
+
+// 3-D Secure statuses of the transactions.
+const (
+	ThreeDSecureNone             ThreeDSecureStatus = 0
+	ThreeDSecureAuthenticated    ThreeDSecureStatus = 1
+	ThreeDSecureAttempted        ThreeDSecureStatus = 2
+	ThreeDSecureNotAuthenticated ThreeDSecureStatus = 3
+	ThreeDSecureUnavailable      ThreeDSecureStatus = 4
+	ThreeDSecureRejected         ThreeDSecureStatus = 5
+)
+
+func (s ThreeDSecureStatus) String() string {
+	switch s {
+	case ThreeDSecureNone:
+		return "None"
+	case ThreeDSecureAuthenticated:
+		return "Authenticated"
+	case ThreeDSecureAttempted:
+		return "Attempted"
+	case ThreeDSecureNotAuthenticated:
+		return "NotAuthenticated"
+	case ThreeDSecureUnavailable:
+		return "Unavailable"
+	case ThreeDSecureRejected:
+		return "Rejected"
+	}
+	return fmt.Sprintf("ThreeDSecureStatus(%d)", int(s))
+}
+
+// LiabilityShift returns true if the authentication shifts the liability for fraud to
+// the issuer, i.e. the cardholder was authenticated or the issuer attempted to.
+func (s ThreeDSecureStatus) LiabilityShift() bool {
+	return s == ThreeDSecureAuthenticated || s == ThreeDSecureAttempted
+}
+
+// ThreeDSecureStatus returns the outcome of the 3-D Secure authentication of the
+// transaction.
+func (r TransactionResponse) ThreeDSecureStatus() ThreeDSecureStatus {
+	return ThreeDSecureStatus(r.ThreeDSecureStatusID)
+}
+
+// CardChargeToken holds the card details entered by the customer in your payment form.
+type CardChargeToken struct {
+	Amount          int64  `json:"amount"`
+	Number          string `json:"number"`
+	CVC             string `json:"cvc"`
+	HolderName      string `json:"holderName"`
+	ExpirationYear  int    `json:"expirationYear"`
+	ExpirationMonth int    `json:"expirationMonth"`
+	// SessionRedirectURL is the page of your site the customer is sent back to once
+	// the 3-D Secure challenge is complete.
+	SessionRedirectURL string `json:"sessionRedirectUrl"`
+}
+
+// CreateChargeToken creates a charge token from the card details of the customer. When
+// the issuer requires the customer to authenticate, the response holds the 3-D Secure
+// challenge to show to the customer, see ChargeTokenResponse.ChallengeRequired. The
+// token is used to Charge the customer once the challenge is complete.
+// Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Native-Checkout-v2/paths/~1nativecheckout~1v2~1chargetokens/post
+func (s *NativeCheckoutService) CreateChargeToken(ctx context.Context, payload CardChargeToken) (*ChargeTokenResponse, error) {
+	ctx = withOperation(ctx, "CreateChargeToken")
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := service(*s).oauthClient(ctx, ScopeNativeCheckout)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := chargeTokenUri(c.Config)
+	body, err := jsonBody(payload, "charge token")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &ChargeTokenResponse{}
+	reqErr := c.do(ctx, "POST", uri, body, t)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	return t, nil
+}
+
+func chargeTokenUri(c Config) string {
+	return fmt.Sprintf("%s/nativecheckout/v2/chargetokens", ApiUri(c))
+}
+
+// ChallengeRequired returns true if the customer must complete a 3-D Secure challenge
+// before the token is charged.
+func (t ChargeTokenResponse) ChallengeRequired() bool {
+	return t.RedirectToACSForm != ""
+}
+
+// WriteChallenge writes the html page redirecting the customer to the 3-D Secure
+// challenge of the issuer. It is typically served in an iframe of your payment form.
+// The customer is sent back to the SessionRedirectURL of the charge token when done.
+func (t ChargeTokenResponse) WriteChallenge(w http.ResponseWriter) error {
+	if !t.ChallengeRequired() {
+		return fmt.Errorf("charge token requires no challenge")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, err := io.WriteString(w, t.RedirectToACSForm)
+	return err
+}
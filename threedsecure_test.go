@@ -0,0 +1,143 @@
+package vivawallet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validCardChargeToken() CardChargeToken {
+	return CardChargeToken{
+		Amount:             1000,
+		Number:             "4111111111111111",
+		CVC:                "123",
+		HolderName:         "Jane Doe",
+		ExpirationYear:     time.Now().Year() + 1,
+		ExpirationMonth:    12,
+		SessionRedirectURL: "https://shop.example.com/3ds",
+	}
+}
+
+func TestCreateChargeToken(t *testing.T) {
+	rec := &recorder{body: `{"chargeToken":"ctok_1","redirectToACSForm":"<form action=\"https://acs.example.com\"></form>"}`}
+	api := newTestAPI(t, rec.handle)
+
+	payload := validCardChargeToken()
+	r, err := api.NativeCheckout.CreateChargeToken(context.Background(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ChargeToken != "ctok_1" || !r.ChallengeRequired() {
+		t.Errorf("CreateChargeToken() = %+v, want ctok_1 with a challenge", r)
+	}
+
+	req := rec.only(t)
+	if req.method != "POST" || req.path != "/nativecheckout/v2/chargetokens" {
+		t.Errorf("sent %s %s", req.method, req.path)
+	}
+	want := map[string]interface{}{
+		"amount":             float64(1000),
+		"number":             "4111111111111111",
+		"cvc":                "123",
+		"holderName":         "Jane Doe",
+		"expirationYear":     float64(payload.ExpirationYear),
+		"expirationMonth":    float64(12),
+		"sessionRedirectUrl": "https://shop.example.com/3ds",
+	}
+	if !reflect.DeepEqual(req.body, want) {
+		t.Errorf("sent %v, want %v", req.body, want)
+	}
+}
+
+func TestCreateChargeTokenErrors(t *testing.T) {
+	t.Run("status", func(t *testing.T) {
+		rec := &recorder{status: http.StatusBadRequest}
+		api := newTestAPI(t, rec.handle)
+		if _, err := api.NativeCheckout.CreateChargeToken(context.Background(), validCardChargeToken()); err == nil || !strings.Contains(err.Error(), "status 400") {
+			t.Errorf("error %v, want the status", err)
+		}
+	})
+
+	t.Run("response", func(t *testing.T) {
+		rec := &recorder{body: `{"chargeToken":1}`}
+		api := newTestAPI(t, rec.handle)
+		if _, err := api.NativeCheckout.CreateChargeToken(context.Background(), validCardChargeToken()); err == nil {
+			t.Error("succeeded with an invalid response")
+		}
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		rec := &recorder{}
+		api := newTestAPI(t, rec.handle)
+		grantScopes(t, api, ScopeAcquiring)
+
+		var scopeErr *ScopeError
+		if _, err := api.NativeCheckout.CreateChargeToken(context.Background(), validCardChargeToken()); !errors.As(err, &scopeErr) {
+			t.Errorf("error %v, want a ScopeError", err)
+		}
+		if rec.count() != 0 {
+			t.Errorf("sent %d requests without the scope", rec.count())
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		rec := &recorder{}
+		api := newTestAPI(t, rec.handle)
+
+		payload := validCardChargeToken()
+		payload.Number = "4111111111111112"
+		payload.CVC = "12"
+		payload.ExpirationYear = 2020
+		payload.SessionRedirectURL = "/3ds"
+		_, err := api.NativeCheckout.CreateChargeToken(context.Background(), payload)
+		if got, want := invalidFields(t, err), []string{"number", "cvc", "expirationYear", "sessionRedirectUrl"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("invalid fields %v, want %v", got, want)
+		}
+		if rec.count() != 0 {
+			t.Errorf("sent %d invalid requests", rec.count())
+		}
+	})
+}
+
+func TestWriteChallenge(t *testing.T) {
+	form := `<form action="https://acs.example.com"></form>`
+	w := httptest.NewRecorder()
+	if err := (ChargeTokenResponse{ChargeToken: "ctok_1", RedirectToACSForm: form}).WriteChallenge(w); err != nil {
+		t.Fatal(err)
+	}
+	if w.Body.String() != form || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") || w.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("WriteChallenge() wrote %q with headers %v", w.Body.String(), w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	if err := (ChargeTokenResponse{ChargeToken: "ctok_1"}).WriteChallenge(w); err == nil || w.Body.Len() != 0 {
+		t.Errorf("WriteChallenge() without a challenge returned %v and wrote %q", err, w.Body.String())
+	}
+}
+
+func TestThreeDSecureStatus(t *testing.T) {
+	tests := []struct {
+		id     int
+		name   string
+		shifts bool
+	}{
+		{0, "None", false},
+		{1, "Authenticated", true},
+		{2, "Attempted", true},
+		{3, "NotAuthenticated", false},
+		{4, "Unavailable", false},
+		{5, "Rejected", false},
+		{9, "ThreeDSecureStatus(9)", false},
+	}
+	for _, tt := range tests {
+		s := TransactionResponse{ThreeDSecureStatusID: tt.id}.ThreeDSecureStatus()
+		if s.String() != tt.name || s.LiabilityShift() != tt.shifts {
+			t.Errorf("status %d = %s, liability shift %t, want %s, %t", tt.id, s, s.LiabilityShift(), tt.name, tt.shifts)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package vivawallet
+
+import (
+	"context"
+	"fmt"
+)
+
+// TipAdjustment sets the tip of an authorized transaction before it is captured.
+// TipAmount is in cents and replaces the tip set when the payment was created.
+type TipAdjustment struct {
+	TipAmount int64 `json:"tipAmount"`
+}
+
+type TipAdjustmentResponse struct {
+	TransactionID string `json:"transactionId"`
+	// Amount is the amount to be captured, tip included, in cents.
+	Amount    int64  `json:"amount"`
+	TipAmount int64  `json:"tipAmount"`
+	StatusID  string `json:"statusId"`
+}
+
+// AdjustTip changes the tip of a pre-authorization before it is captured, e.g. once the
+// customer of a restaurant has added a tip to the receipt. The order must have been
+// created with AllowTipAdjustment. The tip is checked against the authorized amount of
+// the transaction, which is fetched first.
+// Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Transactions
+func (s *TransactionsService) AdjustTip(ctx context.Context, id string, payload TipAdjustment) (*TipAdjustmentResponse, error) {
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+
+	trx, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := payload.validateAgainst(*trx); err != nil {
+		return nil, err
+	}
+
+	ctx = withOperation(ctx, "AdjustTip", Attribute{Key: AttrTransactionID, Value: id})
+	c, err := service(*s).oauthClient(ctx, ScopeAcquiringTransactions)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := adjustTipUri(c.Config, id)
+	body, err := jsonBody(payload, "tip adjustment")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &TipAdjustmentResponse{}
+	reqErr := c.do(ctx, "POST", uri, body, r)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	return r, nil
+}
+
+func adjustTipUri(c Config, id string) string {
+	return fmt.Sprintf("%s/acquiring/v1/transactions/%s:adjusttip", ApiUri(c), id)
+}
@@ -0,0 +1,95 @@
+package vivawallet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTipAdjustmentValidateAgainst(t *testing.T) {
+	preAuth := func(amount float64) GetTransactionResponse {
+		return GetTransactionResponse{Amount: amount, TransactionTypeID: int(TransactionTypeCardPreAuth)}
+	}
+
+	tests := []struct {
+		name string
+		tip  int64
+		trx  GetTransactionResponse
+		want []string
+	}{
+		{"below the authorized amount", 500, preAuth(10), nil},
+		{"equal to the authorized amount", 1000, preAuth(10), nil},
+		{"above the authorized amount", 1001, preAuth(10), []string{"tipAmount"}},
+		{"authorized amount in cents", 29, preAuth(0.29), nil},
+		{"charge", 100, GetTransactionResponse{Amount: 10, TransactionTypeID: int(TransactionTypeCardCharge)}, []string{"transactionId"}},
+		{"capture above the amount", 2000, GetTransactionResponse{Amount: 10}, []string{"transactionId", "tipAmount"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := TipAdjustment{TipAmount: tt.tip}.validateAgainst(tt.trx)
+			if got := invalidFields(t, err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateAgainst() fails on %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// tipServer serves a pre-authorization of 12.50 and its tip adjustment, counting the
+// adjustments.
+func tipServer(t *testing.T, adjusted *int32) *API {
+	t.Helper()
+	return newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/checkout/v2/transactions/trx-id":
+			_, _ = w.Write([]byte(`{"amount":12.5,"statusId":"F","transactionTypeId":1}`))
+		case r.Method == "POST" && r.URL.Path == "/acquiring/v1/transactions/trx-id:adjusttip":
+			atomic.AddInt32(adjusted, 1)
+
+			var payload TipAdjustment
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(TipAdjustmentResponse{
+				TransactionID: "trx-id",
+				Amount:        1250 + payload.TipAmount,
+				TipAmount:     payload.TipAmount,
+				StatusID:      "A",
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestAdjustTip(t *testing.T) {
+	var adjusted int32
+	api := tipServer(t, &adjusted)
+
+	r, err := api.Transactions.AdjustTip(context.Background(), "trx-id", TipAdjustment{TipAmount: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Amount != 1450 || r.TipAmount != 200 {
+		t.Errorf("AdjustTip() = %+v, want an amount of 1450 with a tip of 200", r)
+	}
+	if n := atomic.LoadInt32(&adjusted); n != 1 {
+		t.Errorf("adjusted %d times, want 1", n)
+	}
+}
+
+func TestAdjustTipAboveAuthorizedAmount(t *testing.T) {
+	var adjusted int32
+	api := tipServer(t, &adjusted)
+
+	_, err := api.Transactions.AdjustTip(context.Background(), "trx-id", TipAdjustment{TipAmount: 1251})
+	if got := invalidFields(t, err); !reflect.DeepEqual(got, []string{"tipAmount"}) {
+		t.Errorf("AdjustTip() fails on %q, want tipAmount", got)
+	}
+	if n := atomic.LoadInt32(&adjusted); n != 0 {
+		t.Errorf("adjusted %d times, want none", n)
+	}
+}
@@ -0,0 +1,171 @@
+package vivawallet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Token is an OAuth access token, its expiration time and the scopes it was granted.
+type Token struct {
+	Value   string    `json:"value"`
+	Expires time.Time `json:"expires"`
+	Scopes  []string  `json:"scopes,omitempty"`
+}
+
+// TokenStore persists the OAuth tokens of the clients, so that they can be shared between
+// processes or survive restarts. Tokens are stored under a key identifying the
+// credentials they were issued for. Load returns a nil token and no error when there is
+// no token for the key.
+type TokenStore interface {
+	Load(key string) (*Token, error)
+	Store(key string, t Token) error
+}
+
+// MemoryTokenStore keeps the tokens in memory. It is the default store of the clients.
+type MemoryTokenStore struct {
+	lock   sync.RWMutex
+	tokens map[string]Token
+}
+
+// NewMemoryTokenStore creates an empty in-memory token store.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: map[string]Token{},
+	}
+}
+
+func (s *MemoryTokenStore) Load(key string) (*Token, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	t, ok := s.tokens[key]
+	if !ok {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+func (s *MemoryTokenStore) Store(key string, t Token) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.tokens[key] = t
+	return nil
+}
+
+// FileTokenStore keeps the tokens in a directory, e.g. on a volume shared by several
+// processes, one json file per key. Every file is replaced atomically when written, so
+// readers never observe a partially written token and processes storing different keys
+// never overwrite each other. The tokens are cached in memory and read again only when
+// they expire or their file changes.
+type FileTokenStore struct {
+	Dir    string
+	lock   sync.Mutex
+	tokens map[string]cachedToken
+}
+
+type cachedToken struct {
+	token   Token
+	modTime time.Time
+}
+
+// NewFileTokenStore creates a token store backed by the files of dir. The directory and
+// the files are created on the first write.
+func NewFileTokenStore(dir string) *FileTokenStore {
+	return &FileTokenStore{Dir: dir}
+}
+
+func (s *FileTokenStore) Load(key string) (*Token, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	path := s.path(key)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token %s", err)
+	}
+
+	if c, ok := s.tokens[key]; ok && c.modTime.Equal(info.ModTime()) && time.Now().Before(c.token.Expires) {
+		t := c.token
+		return &t, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token %s", err)
+	}
+
+	var t Token
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse token %s", err)
+	}
+	s.cache(key, t, info.ModTime())
+	return &t, nil
+}
+
+func (s *FileTokenStore) Store(key string, t Token) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to encode token %s", err)
+	}
+
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to write token %s", err)
+	}
+	path := s.path(key)
+	tmp, err := os.CreateTemp(s.Dir, filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write token %s", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write token %s", err)
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write token %s", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write token %s", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write token %s", err)
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		s.cache(key, t, info.ModTime())
+	}
+	return nil
+}
+
+func (s *FileTokenStore) cache(key string, t Token, modTime time.Time) {
+	if s.tokens == nil {
+		s.tokens = map[string]cachedToken{}
+	}
+	s.tokens[key] = cachedToken{token: t, modTime: modTime}
+}
+
+// path returns the file of key. Keys are hashed since they may contain characters which
+// are not allowed in file names.
+func (s *FileTokenStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,95 @@
+package vivawallet
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileTokenStoreKeepsConcurrentKeys(t *testing.T) {
+	dir := t.TempDir()
+	expires := time.Now().Add(time.Hour)
+
+	// Each store stands for a process sharing the directory.
+	var wg sync.WaitGroup
+	keys := []string{"client-a", "client-b", "tenant/c:client"}
+	for _, key := range keys {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			if err := NewFileTokenStore(dir).Store(key, Token{Value: key, Expires: expires}); err != nil {
+				t.Error(err)
+			}
+		}(key)
+	}
+	wg.Wait()
+
+	s := NewFileTokenStore(dir)
+	for _, key := range keys {
+		tok, err := s.Load(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok == nil || tok.Value != key {
+			t.Errorf("Load(%q) = %v, want its token", key, tok)
+		}
+	}
+
+	tok, err := s.Load("missing")
+	if err != nil || tok != nil {
+		t.Errorf("Load(missing) = %v, %v, want no token", tok, err)
+	}
+}
+
+func TestFileTokenStoreReloadsChangedFile(t *testing.T) {
+	dir := t.TempDir()
+	a, b := NewFileTokenStore(dir), NewFileTokenStore(dir)
+
+	if err := a.Store("client", Token{Value: "old", Expires: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if tok, _ := a.Load("client"); tok == nil || tok.Value != "old" {
+		t.Fatalf("Load() = %v, want old", tok)
+	}
+
+	if err := b.Store("client", Token{Value: "new", Expires: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	// Make sure the change is visible on file systems with a coarse mtime.
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(a.path("client"), later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if tok, _ := a.Load("client"); tok == nil || tok.Value != "new" {
+		t.Errorf("Load() = %v, want new", tok)
+	}
+}
+
+func TestFileTokenStoreCachesToken(t *testing.T) {
+	s := NewFileTokenStore(t.TempDir())
+	if err := s.Store("client", Token{Value: "cached", Expires: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Corrupting the file without changing its mtime must not be noticed.
+	info, err := os.Stat(s.path("client"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(s.path("client"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(s.path("client"), info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	tok, err := s.Load("client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.Value != "cached" {
+		t.Errorf("Load() = %v, want the cached token", tok)
+	}
+}
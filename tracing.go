@@ -0,0 +1,111 @@
+package vivawallet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Tracer starts the spans of the requests to Viva and propagates the trace context
+// with them. See the vivaotel package for an OpenTelemetry implementation.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a key value pair describing a span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attributes of the spans.
+const (
+	AttrMerchantID    = "viva.merchant_id"
+	AttrClientID      = "viva.client_id"
+	AttrOrderCode     = "viva.order_code"
+	AttrTransactionID = "viva.transaction_id"
+	AttrErrorCode     = "viva.error_code"
+	AttrHTTPMethod    = "http.request.method"
+	AttrHTTPStatus    = "http.response.status_code"
+	AttrURL           = "url.full"
+)
+
+// operation names the api call a request is performed for.
+type operation struct {
+	name  string
+	attrs []Attribute
+}
+
+type operationKey struct{}
+
+// withOperation names the requests performed with ctx after the api call, e.g.
+// `CreateOrderPayment`.
+func withOperation(ctx context.Context, name string, attrs ...Attribute) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation{name: name, attrs: attrs})
+}
+
+func operationFrom(ctx context.Context) operation {
+	if op, ok := ctx.Value(operationKey{}).(operation); ok {
+		return op
+	}
+	return operation{name: "Request"}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+func (noopSpan) RecordError(err error)            {}
+func (noopSpan) End()                             {}
+
+// startSpan starts the span of a request and injects its context in the request
+// headers.
+func (h hooks) startSpan(req *http.Request) (*http.Request, Span) {
+	if h.tracer == nil {
+		return req, noopSpan{}
+	}
+
+	op := operationFrom(req.Context())
+	attrs := append([]Attribute{
+		{Key: AttrHTTPMethod, Value: req.Method},
+		{Key: AttrURL, Value: req.URL.Redacted()},
+	}, h.attrs...)
+	attrs = append(attrs, op.attrs...)
+
+	ctx, span := h.tracer.Start(req.Context(), "viva."+op.name, attrs...)
+	req = req.WithContext(ctx)
+	h.tracer.Inject(ctx, req.Header)
+	return req, span
+}
+
+// responseAttributes picks the identifiers and the error code out of a response body.
+func responseAttributes(status int, body []byte) []Attribute {
+	attrs := []Attribute{{Key: AttrHTTPStatus, Value: status}}
+
+	var r struct {
+		OrderCode     int64  `json:"orderCode"`
+		TransactionID string `json:"transactionId"`
+		ErrorCode     int    `json:"ErrorCode"`
+	}
+	if json.Unmarshal(body, &r) != nil {
+		return attrs
+	}
+
+	if r.OrderCode != 0 {
+		attrs = append(attrs, Attribute{Key: AttrOrderCode, Value: r.OrderCode})
+	}
+	if r.TransactionID != "" {
+		attrs = append(attrs, Attribute{Key: AttrTransactionID, Value: r.TransactionID})
+	}
+	if r.ErrorCode != 0 {
+		attrs = append(attrs, Attribute{Key: AttrErrorCode, Value: r.ErrorCode})
+	}
+	return attrs
+}
@@ -0,0 +1,138 @@
+package vivawallet
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeTracer records the spans it starts and propagates their name in a header.
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+type fakeSpan struct {
+	name   string
+	attrs  map[string]interface{}
+	errors []error
+	ended  bool
+}
+
+type fakeSpanKey struct{}
+
+func (t *fakeTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &fakeSpan{name: name, attrs: map[string]interface{}{}}
+	s.SetAttributes(attrs...)
+
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return context.WithValue(ctx, fakeSpanKey{}, s), s
+}
+
+func (t *fakeTracer) Inject(ctx context.Context, header http.Header) {
+	if s, ok := ctx.Value(fakeSpanKey{}).(*fakeSpan); ok {
+		header.Set("Traceparent", s.name)
+	}
+}
+
+func (s *fakeSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *fakeSpan) RecordError(err error) { s.errors = append(s.errors, err) }
+func (s *fakeSpan) End()                  { s.ended = true }
+
+// span returns the span of the request sent to the given path.
+func (t *fakeTracer) span(tb testing.TB, path string) *fakeSpan {
+	tb.Helper()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, s := range t.spans {
+		if url, _ := s.attrs[AttrURL].(string); strings.HasSuffix(url, path) {
+			return s
+		}
+	}
+	tb.Fatalf("no span for %s in %d spans", path, len(t.spans))
+	return nil
+}
+
+func TestTracing(t *testing.T) {
+	traceparent := make(chan string, 1)
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		traceparent <- r.Header.Get("Traceparent")
+		_, _ = w.Write([]byte(`{"orderCode":1272214778972604,"transactionId":"trx-1"}`))
+	})
+	tracer := &fakeTracer{}
+	api.Transactions.oauth.Tracer = tracer
+
+	if _, err := api.Transactions.Get(context.Background(), "trx-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	s := tracer.span(t, "/checkout/v2/transactions/trx-1")
+	if s.name != "viva.GetTransaction" {
+		t.Errorf("span name = %s, want viva.GetTransaction", s.name)
+	}
+	if got := <-traceparent; got != s.name {
+		t.Errorf("request propagated %q, want the span %s", got, s.name)
+	}
+
+	want := map[string]interface{}{
+		AttrHTTPMethod:    "GET",
+		AttrHTTPStatus:    200,
+		AttrClientID:      "client-id",
+		AttrTransactionID: "trx-1",
+		AttrOrderCode:     int64(1272214778972604),
+	}
+	for k, v := range want {
+		if s.attrs[k] != v {
+			t.Errorf("span attribute %s = %v (%T), want %v (%T)", k, s.attrs[k], s.attrs[k], v, v)
+		}
+	}
+	if len(s.errors) != 0 || !s.ended {
+		t.Errorf("span errors %v, ended %t, want no errors and ended", s.errors, s.ended)
+	}
+}
+
+func TestTracingRecordsErrors(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"ErrorCode":403,"ErrorText":"forbidden"}`))
+	})
+	tracer := &fakeTracer{}
+	api.Wallets.basic.Tracer = tracer
+
+	if _, err := api.Wallets.List(context.Background()); err == nil {
+		t.Fatal("List() succeeded, want an error")
+	}
+
+	s := tracer.span(t, "/api/wallets")
+	if s.name != "viva.GetWallets" {
+		t.Errorf("span name = %s, want viva.GetWallets", s.name)
+	}
+	if s.attrs[AttrHTTPStatus] != http.StatusBadRequest || s.attrs[AttrErrorCode] != 403 || s.attrs[AttrMerchantID] != "merchant-id" {
+		t.Errorf("span attributes = %v, want status 400, error code 403 and the merchant", s.attrs)
+	}
+	if len(s.errors) != 1 || !strings.Contains(s.errors[0].Error(), "400") || !s.ended {
+		t.Errorf("span errors %v, ended %t, want the status recorded and ended", s.errors, s.ended)
+	}
+}
+
+func TestTracingNamesRequestsWithoutOperation(t *testing.T) {
+	tracer := &fakeTracer{}
+	h := hooks{tracer: tracer}
+
+	req, _ := http.NewRequest("GET", "https://example.com/path", nil)
+	_, span := h.startSpan(req)
+	span.End()
+
+	if s := tracer.span(t, "/path"); s.name != "viva.Request" {
+		t.Errorf("span name = %s, want viva.Request", s.name)
+	}
+}
@@ -1,9 +1,10 @@
 package vivawallet
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
+	"math"
+	"net/url"
 	"time"
 )
 
@@ -29,19 +30,22 @@ type GetTransactionResponse struct {
 	DigitalWalletID     int       `json:"digitalWalletId"`
 }
 
-// GetTransaction fetches a transaction given an ID.
+// TransactionsService handles the transaction endpoints.
+type TransactionsService service
+
+// Get fetches a transaction given an ID.
 // Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Transactions/paths/~1checkout~1v2~1transactions~1{transactionId}/get
-func (c OAuthClient) GetTransaction(trxID string) (*GetTransactionResponse, error) {
-	// TODO: use RoundTripper to avoid rewriting this
-	if c.HasAuthExpired() {
-		_, authErr := c.Authenticate()
-		return nil, fmt.Errorf("authentication error %s", authErr)
+func (s *TransactionsService) Get(ctx context.Context, trxID string) (*GetTransactionResponse, error) {
+	ctx = withOperation(ctx, "GetTransaction", Attribute{Key: AttrTransactionID, Value: trxID})
+	c, err := service(*s).oauthClient(ctx, ScopeRedirectCheckout)
+	if err != nil {
+		return nil, err
 	}
 
 	uri := getTransactionUri(c.Config, trxID)
 
 	trx := &GetTransactionResponse{}
-	reqErr := c.Get(uri, &trx)
+	reqErr := c.do(ctx, "GET", uri, nil, trx)
 	if reqErr != nil {
 		return nil, reqErr
 	}
@@ -49,6 +53,13 @@ func (c OAuthClient) GetTransaction(trxID string) (*GetTransactionResponse, erro
 	return trx, nil
 }
 
+// GetTransaction fetches a transaction given an ID.
+//
+// Deprecated: use API.Transactions.Get instead.
+func (c OAuthClient) GetTransaction(trxID string) (*GetTransactionResponse, error) {
+	return NewAPI(&c, nil).Transactions.Get(context.Background(), trxID)
+}
+
 func getTransactionUri(c Config, trxID string) string {
 	return fmt.Sprintf("%s/checkout/v2/transactions/%s", ApiUri(c), trxID)
 }
@@ -63,7 +74,7 @@ type CreateTransaction struct {
 }
 
 type TransactionResponse struct {
-	Emv                      string   `json:"Emv,omitempty"`
+	Emv                      string    `json:"Emv,omitempty"`
 	Amount                   float64   `json:"Amount"`
 	StatusID                 string    `json:"StatusId,omitempty"`
 	CurrencyCode             string    `json:"CurrencyCode,omitempty"`
@@ -80,38 +91,124 @@ type TransactionResponse struct {
 	Success                  bool      `json:"Success,omitempty"`
 }
 
-// CreateTransaction creates a new transaction for a recurring payment or a pre-auth
-// order payment
+// Create creates a new transaction for a recurring payment or a pre-auth order payment,
+// using the transaction with the given id as the initial one. See WithIdempotencyKey to
+// avoid charging twice when retrying. When the outcome of a previous call with the same
+// key is unknown, the transactions created since are looked up by MerchantTrns before
+// calling again.
 // Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Transactions-(Deprecated)/paths/~1api~1transactions~1{transaction_id}/post
-func (c BasicAuthClient) CreateTransaction(id string, payload CreateTransaction) (*TransactionResponse, error) {
+func (s *TransactionsService) Create(ctx context.Context, id string, payload CreateTransaction) (*TransactionResponse, error) {
+	ctx = withOperation(ctx, "CreateTransaction", Attribute{Key: AttrTransactionID, Value: id})
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return nil, err
+	}
+
 	uri := getCreateTransactionUri(c.Config, id)
-	data, err := json.Marshal(payload)
+	body, err := jsonBody(payload, "transaction")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create transaction %s", err.Error())
+		return nil, err
 	}
 
-	trx := &TransactionResponse{}
-	reqErr := c.Post(uri, bytes.NewReader(data), &trx)
+	return idempotent(ctx, c.Idempotency, c.Config.MerchantID, "CreateTransaction", func(ctx context.Context) (*TransactionResponse, error) {
+		trx := &TransactionResponse{}
+		reqErr := c.do(ctx, "POST", uri, body, trx)
+		if reqErr != nil {
+			return nil, reqErr
+		}
 
-	if reqErr != nil {
-		return nil, reqErr
+		return trx, nil
+	}, func(since time.Time) (*TransactionResponse, error) {
+		return s.findCreated(ctx, id, payload, since)
+	})
+}
+
+// clockSkew is the tolerance on the creation time of the transactions looked up by
+// findCreated, since the clocks of Viva and of the caller differ.
+const clockSkew = time.Minute
+
+// findCreated looks for a transaction created from the initial transaction id since the
+// given time, with the merchant reference and the amount of the payload, i.e. the
+// outcome of a create call that timed out. Payloads without a merchant reference cannot
+// be looked up.
+func (s *TransactionsService) findCreated(ctx context.Context, id string, payload CreateTransaction, since time.Time) (*TransactionResponse, error) {
+	if payload.MerchantTrns == "" {
+		return nil, nil
 	}
 
-	return trx, nil
+	since = since.Add(-clockSkew)
+	today := time.Now()
+	for day := since; !dateAfter(day, today); day = day.AddDate(0, 0, 1) {
+		trxs, err := s.List(ctx, ListTransactionsOptions{Date: day})
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up transaction %s", err)
+		}
+
+		for _, t := range trxs {
+			if t.ParentID != id || t.InsDate.Before(since) {
+				continue
+			}
+			if t.MerchantTrns == payload.MerchantTrns && toCents(t.Amount) == payload.Amount {
+				return &TransactionResponse{
+					Amount:        t.Amount,
+					StatusID:      t.StatusID,
+					CurrencyCode:  t.CurrencyCode,
+					TransactionID: t.TransactionID,
+					Timestamp:     t.InsDate,
+					Success:       true,
+				}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// dateAfter reports whether the date of a is after the date of b.
+func dateAfter(a time.Time, b time.Time) bool {
+	return a.Format("2006-01-02") > b.Format("2006-01-02")
+}
+
+// toCents converts an amount of the responses to the cents of the requests.
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// CreateTransaction creates a new transaction for a recurring payment or a pre-auth
+// order payment.
+//
+// Deprecated: use API.Transactions.Create instead.
+func (c BasicAuthClient) CreateTransaction(id string, payload CreateTransaction) (*TransactionResponse, error) {
+	return NewAPI(nil, &c).Transactions.Create(context.Background(), id, payload)
 }
 
 func getCreateTransactionUri(c Config, id string) string {
 	return fmt.Sprintf("%s/api/transactions/%s", AppUri(c), id)
 }
 
-// CancelTransaction cancels a transaction
+// CancelOptions describes the amount to cancel or refund and the source it belongs to.
+// A zero SourceCode uses the default source of the merchant.
+type CancelOptions struct {
+	Amount     int64
+	SourceCode string
+}
+
+// Cancel cancels or refunds a transaction.
 // Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Transactions-(Deprecated)/paths/~1api~1transactions~1{transaction_id}/delete
-func (c BasicAuthClient) CancelTransaction(id string, amount int64, sourceCode string) (*TransactionResponse, error) {
-	uri := getCancelTransactionUri(c.Config, id, amount, sourceCode)
+func (s *TransactionsService) Cancel(ctx context.Context, id string, opts CancelOptions) (*TransactionResponse, error) {
+	ctx = withOperation(ctx, "CancelTransaction", Attribute{Key: AttrTransactionID, Value: id})
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return nil, err
+	}
 
-	trx := &TransactionResponse{}
-	reqErr := c.Delete(uri, nil, &trx)
+	uri := getCancelTransactionUri(c.Config, id, opts.Amount, opts.SourceCode)
 
+	trx := &TransactionResponse{}
+	reqErr := c.do(ctx, "DELETE", uri, nil, trx)
 	if reqErr != nil {
 		return nil, reqErr
 	}
@@ -119,6 +216,14 @@ func (c BasicAuthClient) CancelTransaction(id string, amount int64, sourceCode s
 	return trx, nil
 }
 
+// CancelTransaction cancels a transaction.
+//
+// Deprecated: use API.Transactions.Cancel instead.
+func (c BasicAuthClient) CancelTransaction(id string, amount int64, sourceCode string) (*TransactionResponse, error) {
+	opts := CancelOptions{Amount: amount, SourceCode: sourceCode}
+	return NewAPI(nil, &c).Transactions.Cancel(context.Background(), id, opts)
+}
+
 func getCancelTransactionUri(c Config, id string, amount int64, sourceCode string) string {
 	var sourceParam = ""
 	if sourceCode != "" {
@@ -128,22 +233,25 @@ func getCancelTransactionUri(c Config, id string, amount int64, sourceCode strin
 	return fmt.Sprintf("%s/api/transactions/%s?amount=%d%s", AppUri(c), id, amount, sourceParam)
 }
 
-// CancelPartialAuthorization cancels a partial authorization
+// CancelAuthorization cancels the given amount of a pre-authorization.
 // Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Transactions-(Deprecated)/paths/~1acquiring~1v1~1transactions~1{transactionId}/delete
-func (c OAuthClient) CancelPartialAuthorization(id string, amount int64, sourceCode string) error {
-	if c.HasAuthExpired() {
-		_, authErr := c.Authenticate()
-		return fmt.Errorf("authentication error %s", authErr)
+func (s *TransactionsService) CancelAuthorization(ctx context.Context, id string, opts CancelOptions) error {
+	ctx = withOperation(ctx, "CancelPartialAuthorization", Attribute{Key: AttrTransactionID, Value: id})
+	c, err := service(*s).oauthClient(ctx, ScopeAcquiring)
+	if err != nil {
+		return err
 	}
-	uri := getCancelPartialAuthUri(c.Config, id, amount, sourceCode)
 
-	var response string
-	reqErr := c.Delete(uri, nil, &response)
-	if reqErr != nil {
-		return reqErr
-	}
+	uri := getCancelPartialAuthUri(c.Config, id, opts.Amount, opts.SourceCode)
+	return c.do(ctx, "DELETE", uri, nil, nil)
+}
 
-	return nil
+// CancelPartialAuthorization cancels a partial authorization.
+//
+// Deprecated: use API.Transactions.CancelAuthorization instead.
+func (c OAuthClient) CancelPartialAuthorization(id string, amount int64, sourceCode string) error {
+	opts := CancelOptions{Amount: amount, SourceCode: sourceCode}
+	return NewAPI(&c, nil).Transactions.CancelAuthorization(context.Background(), id, opts)
 }
 
 func getCancelPartialAuthUri(c Config, id string, amount int64, sourceCode string) string {
@@ -154,3 +262,89 @@ func getCancelPartialAuthUri(c Config, id string, amount int64, sourceCode strin
 
 	return fmt.Sprintf("%s/acquiring/v1/transactions/%s?amount=%d%s", ApiUri(c), id, amount, sourceParam)
 }
+
+// ListTransactionsOptions filters the transactions returned by List. Exactly one of the
+// fields is expected to be set.
+type ListTransactionsOptions struct {
+	Date          time.Time
+	ClearanceDate time.Time
+	OrderCode     int64
+}
+
+type Transaction struct {
+	TransactionID      string    `json:"TransactionId"`
+	ParentID           string    `json:"ParentId"`
+	Amount             float64   `json:"Amount"`
+	StatusID           string    `json:"StatusId"`
+	CurrencyCode       string    `json:"CurrencyCode"`
+	InsDate            time.Time `json:"InsDate"`
+	ClearanceDate      time.Time `json:"ClearanceDate"`
+	SourceCode         string    `json:"SourceCode"`
+	MerchantTrns       string    `json:"MerchantTrns"`
+	CustomerTrns       string    `json:"CustomerTrns"`
+	Commission         float64   `json:"Commission"`
+	TotalInstallments  int       `json:"TotalInstallments"`
+	CurrentInstallment int       `json:"CurrentInstallment"`
+	Order              struct {
+		OrderCode   int64    `json:"OrderCode"`
+		RequestLang string   `json:"RequestLang"`
+		Tags        []string `json:"Tags"`
+	} `json:"Order"`
+	TransactionType struct {
+		TransactionTypeID int    `json:"TransactionTypeId"`
+		Name              string `json:"Name"`
+	} `json:"TransactionType"`
+	CreditCard struct {
+		Number      string `json:"Number"`
+		CountryCode string `json:"CountryCode"`
+		IssuingBank string `json:"IssuingBank"`
+		CardType    struct {
+			CardTypeID int    `json:"CardTypeId"`
+			Name       string `json:"Name"`
+		} `json:"CardType"`
+	} `json:"CreditCard"`
+}
+
+type ListTransactionsResponse struct {
+	Transactions  []Transaction `json:"Transactions"`
+	ErrorCode     int           `json:"ErrorCode"`
+	ErrorText     string        `json:"ErrorText"`
+	TimeStamp     time.Time     `json:"TimeStamp"`
+	CorrelationID string        `json:"CorrelationId"`
+	EventID       int           `json:"EventId"`
+	Success       bool          `json:"Success"`
+}
+
+// List fetches the transactions of a day, of a clearance day or of an order payment.
+// Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Transactions-(Deprecated)/paths/~1api~1transactions/get
+func (s *TransactionsService) List(ctx context.Context, opts ListTransactionsOptions) ([]Transaction, error) {
+	ctx = withOperation(ctx, "ListTransactions")
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	uri := getListTransactionsUri(c.Config, opts)
+
+	r := &ListTransactionsResponse{}
+	reqErr := c.do(ctx, "GET", uri, nil, r)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	return r.Transactions, nil
+}
+
+func getListTransactionsUri(c Config, opts ListTransactionsOptions) string {
+	params := url.Values{}
+	if !opts.Date.IsZero() {
+		params.Set("date", opts.Date.Format("2006-01-02"))
+	}
+	if !opts.ClearanceDate.IsZero() {
+		params.Set("clearancedate", opts.ClearanceDate.Format("2006-01-02"))
+	}
+	if opts.OrderCode != 0 {
+		params.Set("ordercode", fmt.Sprintf("%d", opts.OrderCode))
+	}
+
+	return fmt.Sprintf("%s/api/transactions?%s", AppUri(c), params.Encode())
+}
@@ -0,0 +1,276 @@
+package vivawallet
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits of the request payloads.
+const (
+	MinOrderAmount         = 30
+	MaxDescriptionLength   = 2048
+	MaxInstallments        = 36
+	MaxPaymentTimeout      = 65535
+	MaxEmailLength         = 50
+	ExpirationDateLayout   = "2006-01-02T15:04:05"
+	minPhoneDigits         = 6
+	maxPhoneDigits         = 15
+	maxApplePayDisplayName = 64
+)
+
+var (
+	countryPattern    = regexp.MustCompile(`^[A-Z]{2}$`)
+	phonePattern      = regexp.MustCompile(`^\+[1-9][0-9]+$`)
+	cardNumberPattern = regexp.MustCompile(`^[0-9]{12,19}$`)
+	cvcPattern        = regexp.MustCompile(`^[0-9]{3,4}$`)
+)
+
+// FieldError describes an invalid field of a request payload. Field is the json name of
+// the field, e.g. `customer.email`.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// ValidationError lists the invalid fields of a request payload. It is the error
+// returned by the Validate methods of the payloads.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, f := range e {
+		msgs[i] = f.Error()
+	}
+	return "invalid request: " + strings.Join(msgs, ", ")
+}
+
+// validator collects the field errors of a payload.
+type validator struct {
+	errs ValidationError
+}
+
+func (v *validator) add(field string, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) check(ok bool, field string, format string, args ...interface{}) {
+	if !ok {
+		v.add(field, format, args...)
+	}
+}
+
+func (v *validator) maxLength(field string, value string, max int) {
+	v.check(utf8.RuneCountInString(value) <= max, field, "must be at most %d characters", max)
+}
+
+func (v *validator) email(field string, value string) {
+	if value == "" {
+		return
+	}
+	addr, err := mail.ParseAddress(value)
+	v.check(err == nil && addr.Address == value, field, "must be a valid email address")
+	v.maxLength(field, value, MaxEmailLength)
+}
+
+func (v *validator) phone(field string, value string) {
+	if value == "" {
+		return
+	}
+	n := len(value) - 1
+	v.check(phonePattern.MatchString(value) && n >= minPhoneDigits && n <= maxPhoneDigits, field, "must be an E.164 phone number such as +302101234567")
+}
+
+func (v *validator) requestLang(field string, value string) {
+	if value == "" {
+		return
+	}
+	for _, l := range RequestLangs {
+		if l == value {
+			return
+		}
+	}
+	v.add(field, "must be one of %s", strings.Join(RequestLangs, ", "))
+}
+
+func (v *validator) country(field string, value string) {
+	if value == "" {
+		return
+	}
+	v.check(countryPattern.MatchString(value), field, "must be an ISO 3166-1 alpha-2 country code")
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// Validate checks the order before it is sent to Viva.
+func (o CheckoutOrder) Validate() error {
+	v := &validator{}
+	if o.IsCardVerification {
+		v.check(o.Amount == 0, "amount", "must be 0 for a card verification")
+		v.check(!o.PreAuth, "preauth", "cannot be set for a card verification")
+		v.check(o.MaxInstallments == 0, "maxInstallments", "cannot be set for a card verification")
+	} else {
+		v.check(o.Amount >= MinOrderAmount, "amount", "must be at least %d", MinOrderAmount)
+	}
+	v.check(o.TipAmount >= 0, "tipAmount", "must not be negative")
+	v.check(o.TipAmount <= o.Amount, "tipAmount", "must not exceed the amount")
+	v.maxLength("customerTrns", o.CustomerTransactions, MaxDescriptionLength)
+	v.maxLength("merchantTrns", o.MerchantTransactions, MaxDescriptionLength)
+	o.Customer.validate(v, "customer.")
+	v.check(o.PaymentTimeout >= 0 && o.PaymentTimeout <= MaxPaymentTimeout, "paymentTimeout", "must be between 0 and %d seconds", MaxPaymentTimeout)
+	v.check(o.MaxInstallments >= 0 && o.MaxInstallments <= MaxInstallments, "maxInstallments", "must be between 0 and %d", MaxInstallments)
+	v.check(!o.PreAuth || o.MaxInstallments == 0, "maxInstallments", "cannot be set for a pre-authorization")
+	v.check(!o.ForceMaxInstallments || o.MaxInstallments > 0, "forceMaxInstallments", "requires maxInstallments")
+	v.check(o.CurrencyCode >= 0 && o.CurrencyCode <= 999, "currencyCode", "must be an ISO 4217 numeric code")
+	for i, f := range o.PaymentMethodFees {
+		v.check(f.PaymentMethodID != "", fmt.Sprintf("paymentMethodFees[%d].paymentMethodId", i), "must be set")
+		v.check(f.Fee >= 0, fmt.Sprintf("paymentMethodFees[%d].fee", i), "must not be negative")
+	}
+	if o.KlarnaOrderOptions != nil {
+		for i, l := range o.KlarnaOrderOptions.OrderLines {
+			field := fmt.Sprintf("klarnaOrderOptions.orderLines[%d]", i)
+			v.check(l.Name != "", field+".name", "must be set")
+			v.check(l.Quantity > 0, field+".quantity", "must be positive")
+			v.check(l.TotalAmount >= 0, field+".total_amount", "must not be negative")
+		}
+	}
+	return v.err()
+}
+
+// Validate checks the transaction before it is sent to Viva.
+func (t CreateTransaction) Validate() error {
+	v := &validator{}
+	v.check(t.Amount > 0, "amount", "must be positive")
+	v.check(t.TipAmount >= 0, "tipAmount", "must not be negative")
+	v.check(int64(t.TipAmount) <= t.Amount, "tipAmount", "must not exceed the amount")
+	v.check(t.Installments >= 0 && t.Installments <= MaxInstallments, "installments", "must be between 0 and %d", MaxInstallments)
+	v.maxLength("customerTrns", t.CustomerTrnx, MaxDescriptionLength)
+	v.maxLength("merchantTrns", t.MerchantTrns, MaxDescriptionLength)
+	return v.err()
+}
+
+// Validate checks the update before it is sent to Viva.
+func (u UpdateOrderPayment) Validate() error {
+	v := &validator{}
+	v.check(u.Amount >= 0, "amount", "must not be negative")
+	v.check(u.IsCancelled || u.Amount >= MinOrderAmount, "amount", "must be at least %d", MinOrderAmount)
+	if u.ExpirationDate != "" {
+		_, err := parseExpirationDate(u.ExpirationDate)
+		v.check(err == nil, "expirationDate", "must be a date such as %s", ExpirationDateLayout)
+	}
+	v.check(!u.IsCancelled || !u.DisablePaidState, "disablePaidState", "cannot be set when cancelling the order")
+	return v.err()
+}
+
+// Validate checks the transfer before it is sent to Viva.
+func (b BalanceTransfer) Validate() error {
+	v := &validator{}
+	v.check(b.Amount > 0, "amount", "must be positive")
+	v.maxLength("description", b.Description, MaxDescriptionLength)
+	return v.err()
+}
+
+// Validate checks the charge token before it is sent to Viva.
+func (t WalletChargeToken) Validate() error {
+	v := &validator{}
+	v.check(t.Amount > 0, "amount", "must be positive")
+	v.check(t.Wallet == DigitalWalletApplePay || t.Wallet == DigitalWalletGooglePay, "digitalWalletId", "must be Apple Pay or Google Pay")
+	v.check(len(t.Token) > 0, "token", "must be set")
+	return v.err()
+}
+
+// Validate checks the merchant validation before it is sent to Viva.
+func (a ApplePayMerchantValidation) Validate() error {
+	v := &validator{}
+	u, err := url.Parse(a.ValidationURL)
+	v.check(err == nil && u.Scheme == "https" && u.Host != "", "validationUrl", "must be an https url")
+	v.check(a.DomainName != "", "domainName", "must be set")
+	v.maxLength("displayName", a.DisplayName, maxApplePayDisplayName)
+	return v.err()
+}
+
+// Validate checks the charge before it is sent to Viva.
+func (c Charge) Validate() error {
+	v := &validator{}
+	v.check(c.Amount > 0, "amount", "must be positive")
+	v.check(c.ChargeToken != "", "chargeToken", "must be set")
+	c.Customer.validate(v, "customer.")
+	v.check(c.TipAmount >= 0, "tipAmount", "must not be negative")
+	v.check(c.TipAmount <= c.Amount, "tipAmount", "must not exceed the amount")
+	v.check(c.Installments >= 0 && c.Installments <= MaxInstallments, "installments", "must be between 0 and %d", MaxInstallments)
+	v.check(!c.PreAuth || c.Installments == 0, "installments", "cannot be set for a pre-authorization")
+	v.maxLength("customerTrns", c.CustomerTrns, MaxDescriptionLength)
+	v.maxLength("merchantTrns", c.MerchantTrns, MaxDescriptionLength)
+	return v.err()
+}
+
+// Validate checks the charge token before it is sent to Viva.
+func (t CardChargeToken) Validate() error {
+	v := &validator{}
+	v.check(t.Amount > 0, "amount", "must be positive")
+	v.check(cardNumberPattern.MatchString(t.Number) && luhn(t.Number), "number", "must be a valid card number")
+	v.check(cvcPattern.MatchString(t.CVC), "cvc", "must be 3 or 4 digits")
+	v.check(t.HolderName != "", "holderName", "must be set")
+	v.check(t.ExpirationMonth >= 1 && t.ExpirationMonth <= 12, "expirationMonth", "must be between 1 and 12")
+	now := time.Now()
+	v.check(t.ExpirationYear > now.Year() || t.ExpirationYear == now.Year() && t.ExpirationMonth >= int(now.Month()), "expirationYear", "card has expired")
+	u, err := url.Parse(t.SessionRedirectURL)
+	v.check(err == nil && u.IsAbs() && u.Host != "", "sessionRedirectUrl", "must be an absolute url")
+	return v.err()
+}
+
+// luhn checks the check digit of a card number.
+func luhn(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Validate checks the tip adjustment before it is sent to Viva.
+func (t TipAdjustment) Validate() error {
+	v := &validator{}
+	v.check(t.TipAmount >= 0, "tipAmount", "must not be negative")
+	return v.err()
+}
+
+// validateAgainst checks the tip adjustment against the transaction it adjusts, which
+// must be an authorization of at least the amount of the tip.
+func (t TipAdjustment) validateAgainst(trx GetTransactionResponse) error {
+	v := &validator{}
+	v.check(trx.TransactionType().IsPreAuth(), "transactionId", "must be a pre-authorization, not %s", trx.TransactionType())
+	authorized := toCents(trx.Amount)
+	v.check(t.TipAmount <= authorized, "tipAmount", "must not exceed the authorized amount of %d", authorized)
+	return v.err()
+}
+
+// parseExpirationDate accepts dates with or without fractional seconds and time zone.
+func parseExpirationDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(ExpirationDateLayout+".999999999", s)
+}
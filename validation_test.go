@@ -0,0 +1,130 @@
+package vivawallet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// invalidFields returns the fields listed by a ValidationError.
+func invalidFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+
+	var verr ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("error %v is not a ValidationError", err)
+	}
+	fields := make([]string, len(verr))
+	for i, f := range verr {
+		fields[i] = f.Field
+	}
+	return fields
+}
+
+func TestValidate(t *testing.T) {
+	nextYear := time.Now().Year() + 1
+	card := CardChargeToken{
+		Amount:             100,
+		Number:             "4111111111111111",
+		CVC:                "123",
+		HolderName:         "Jane Doe",
+		ExpirationMonth:    12,
+		ExpirationYear:     nextYear,
+		SessionRedirectURL: "https://example.com/3ds",
+	}
+
+	tests := []struct {
+		name    string
+		payload interface{ Validate() error }
+		want    []string
+	}{
+		{"order", CheckoutOrder{Amount: 1000, Customer: Customer{Email: "user@example.com", Phone: "+302101234567", CountryCode: "GR", RequestLang: "el-GR"}}, nil},
+		{"order below the minimum", CheckoutOrder{Amount: MinOrderAmount - 1}, []string{"amount"}},
+		{"order tip above the amount", CheckoutOrder{Amount: 100, TipAmount: 200}, []string{"tipAmount"}},
+		{"order customer", CheckoutOrder{Amount: 100, Customer: Customer{Email: "Jane <user@example.com>", Phone: "2101234567", CountryCode: "gr", RequestLang: "el"}}, []string{"customer.email", "customer.phone", "customer.countryCode", "customer.requestLang"}},
+		{"order long description", CheckoutOrder{Amount: 100, MerchantTransactions: strings.Repeat("ά", MaxDescriptionLength+1)}, []string{"merchantTrns"}},
+		{"order pre-authorization in installments", CheckoutOrder{Amount: 100, PreAuth: true, MaxInstallments: 3}, []string{"maxInstallments"}},
+		{"order installments", CheckoutOrder{Amount: 100, MaxInstallments: MaxInstallments + 1, ForceMaxInstallments: true}, []string{"maxInstallments"}},
+		{"order forced installments", CheckoutOrder{Amount: 100, ForceMaxInstallments: true}, []string{"forceMaxInstallments"}},
+		{"order payment timeout", CheckoutOrder{Amount: 100, PaymentTimeout: MaxPaymentTimeout + 1}, []string{"paymentTimeout"}},
+		{"order fees", CheckoutOrder{Amount: 100, PaymentMethodFees: []PaymentMethodFee{{Fee: -1}}}, []string{"paymentMethodFees[0].paymentMethodId", "paymentMethodFees[0].fee"}},
+		{"card verification", CheckoutOrder{IsCardVerification: true}, nil},
+		{"card verification with an amount", CheckoutOrder{IsCardVerification: true, Amount: 100, PreAuth: true}, []string{"amount", "preauth"}},
+		{"transaction", CreateTransaction{Amount: 100, Installments: 3}, nil},
+		{"transaction without amount", CreateTransaction{}, []string{"amount"}},
+		{"transaction tip", CreateTransaction{Amount: 100, TipAmount: -1}, []string{"tipAmount"}},
+		{"update", UpdateOrderPayment{Amount: 1000, ExpirationDate: "2026-11-07T10:24:17.61"}, nil},
+		{"update with time zone", UpdateOrderPayment{Amount: 1000, ExpirationDate: "2026-11-07T10:24:17+02:00"}, nil},
+		{"update expiration date", UpdateOrderPayment{Amount: 1000, ExpirationDate: "07/11/2026"}, []string{"expirationDate"}},
+		{"update cancellation", UpdateOrderPayment{IsCancelled: true}, nil},
+		{"update cancellation disabling the paid state", UpdateOrderPayment{IsCancelled: true, DisablePaidState: true}, []string{"disablePaidState"}},
+		{"transfer", BalanceTransfer{Amount: 100, Description: "payout"}, nil},
+		{"transfer without amount", BalanceTransfer{}, []string{"amount"}},
+		{"wallet charge token", WalletChargeToken{Amount: 100, Wallet: DigitalWalletApplePay, Token: []byte(`{}`)}, nil},
+		{"wallet charge token without wallet", WalletChargeToken{Amount: 100}, []string{"digitalWalletId", "token"}},
+		{"merchant validation", ApplePayMerchantValidation{ValidationURL: "https://apple-pay-gateway.apple.com/paymentservices/startSession", DomainName: "example.com"}, nil},
+		{"merchant validation over http", ApplePayMerchantValidation{ValidationURL: "http://example.com", DomainName: "example.com", DisplayName: strings.Repeat("a", 65)}, []string{"validationUrl", "displayName"}},
+		{"charge", Charge{Amount: 100, ChargeToken: "token"}, nil},
+		{"charge pre-authorization in installments", Charge{Amount: 100, ChargeToken: "token", PreAuth: true, Installments: 2}, []string{"installments"}},
+		{"card charge token", card, nil},
+		{"card charge token with a wrong check digit", func() CardChargeToken { c := card; c.Number = "4111111111111112"; return c }(), []string{"number"}},
+		{"card charge token expired", func() CardChargeToken { c := card; c.ExpirationYear = nextYear - 2; return c }(), []string{"expirationYear"}},
+		{"card charge token fields", CardChargeToken{CVC: "12", ExpirationMonth: 13, ExpirationYear: nextYear, SessionRedirectURL: "/3ds"}, []string{"amount", "number", "cvc", "holderName", "expirationMonth", "sessionRedirectUrl"}},
+		{"tip adjustment", TipAdjustment{TipAmount: 0}, nil},
+		{"negative tip adjustment", TipAdjustment{TipAmount: -1}, []string{"tipAmount"}},
+		{"customer", Customer{}, nil},
+		{"customer email too long", Customer{Email: strings.Repeat("a", MaxEmailLength) + "@example.com"}, []string{"email"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := invalidFields(t, tt.payload.Validate()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() fails on %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLuhn(t *testing.T) {
+	tests := map[string]bool{
+		"4111111111111111": true,
+		"5555555555554444": true,
+		"378282246310005":  true,
+		"4111111111111112": false,
+		"6011111111111118": false,
+	}
+	for number, want := range tests {
+		if got := luhn(number); got != want {
+			t.Errorf("luhn(%s) = %v, want %v", number, got, want)
+		}
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := ValidationError{{Field: "amount", Message: "must be positive"}, {Field: "cvc", Message: "must be 3 or 4 digits"}}
+	if want := "invalid request: amount must be positive, cvc must be 3 or 4 digits"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestInvalidPayloadIsNotSent(t *testing.T) {
+	var hits int32
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	})
+
+	_, err := api.Orders.Create(context.Background(), CheckoutOrder{Amount: 1})
+	if got := invalidFields(t, err); !reflect.DeepEqual(got, []string{"amount"}) {
+		t.Errorf("Create() fails on %q, want amount", got)
+	}
+	if n := atomic.LoadInt32(&hits); n != 0 {
+		t.Errorf("sent %d requests, want none", n)
+	}
+}
@@ -0,0 +1,21 @@
+module github.com/techpals-eu/viva-wallet-go/vivaotel
+
+go 1.21
+
+require (
+	github.com/techpals-eu/viva-wallet-go v1.0.4
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// The package is developed along with the root module.
+replace github.com/techpals-eu/viva-wallet-go => ../
@@ -0,0 +1,87 @@
+// Package vivaotel traces the requests of the Viva clients with OpenTelemetry.
+//
+//	oauthClient := vivawallet.NewOAuth(clientID, clientSecret, true)
+//	oauthClient.Tracer = vivaotel.NewTracer(otel.GetTracerProvider())
+package vivaotel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/techpals-eu/viva-wallet-go"
+
+type tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTracer creates a tracer using the given provider, or the global one if nil. The
+// trace context is propagated with the global propagator.
+func NewTracer(tp trace.TracerProvider) vivawallet.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &tracer{
+		tracer:     tp.Tracer(instrumentationName),
+		propagator: otel.GetTextMapPropagator(),
+	}
+}
+
+func (t *tracer) Start(ctx context.Context, name string, attrs ...vivawallet.Attribute) (context.Context, vivawallet.Span) {
+	ctx, s := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(convert(attrs)...),
+	)
+	return ctx, span{s}
+}
+
+func (t *tracer) Inject(ctx context.Context, header http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s span) SetAttributes(attrs ...vivawallet.Attribute) {
+	s.span.SetAttributes(convert(attrs)...)
+}
+
+func (s span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) End() {
+	s.span.End()
+}
+
+func convert(attrs []vivawallet.Attribute) []attribute.KeyValue {
+	r := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			r = append(r, attribute.String(a.Key, v))
+		case int:
+			r = append(r, attribute.Int(a.Key, v))
+		case int64:
+			r = append(r, attribute.Int64(a.Key, v))
+		case bool:
+			r = append(r, attribute.Bool(a.Key, v))
+		case float64:
+			r = append(r, attribute.Float64(a.Key, v))
+		default:
+			r = append(r, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	return r
+}
@@ -0,0 +1,82 @@
+package vivaotel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := &tracer{
+		tracer:     tp.Tracer(instrumentationName),
+		propagator: propagation.TraceContext{},
+	}
+
+	ctx, s := tr.Start(context.Background(), "viva.GetTransaction",
+		vivawallet.Attribute{Key: vivawallet.AttrHTTPMethod, Value: "GET"},
+		vivawallet.Attribute{Key: vivawallet.AttrOrderCode, Value: int64(1272214778972604)},
+	)
+	header := http.Header{}
+	tr.Inject(ctx, header)
+	s.SetAttributes(
+		vivawallet.Attribute{Key: vivawallet.AttrHTTPStatus, Value: 500},
+		vivawallet.Attribute{Key: "retried", Value: true},
+		vivawallet.Attribute{Key: "amount", Value: 10.5},
+		vivawallet.Attribute{Key: "other", Value: []int{1}},
+	)
+	s.RecordError(errors.New("status 500"))
+	s.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "viva.GetTransaction" || span.SpanKind() != trace.SpanKindClient {
+		t.Errorf("span %s of kind %s, want the client span viva.GetTransaction", span.Name(), span.SpanKind())
+	}
+
+	want := map[attribute.Key]attribute.Value{
+		vivawallet.AttrHTTPMethod: attribute.StringValue("GET"),
+		vivawallet.AttrOrderCode:  attribute.Int64Value(1272214778972604),
+		vivawallet.AttrHTTPStatus: attribute.IntValue(500),
+		"retried":                 attribute.BoolValue(true),
+		"amount":                  attribute.Float64Value(10.5),
+		"other":                   attribute.StringValue("[1]"),
+	}
+	got := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		got[kv.Key] = kv.Value
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("attribute %s = %v, want %v", k, got[k].Emit(), v.Emit())
+		}
+	}
+
+	if span.Status().Code != codes.Error || span.Status().Description != "status 500" || len(span.Events()) != 1 {
+		t.Errorf("span status %+v with %d events, want the recorded error", span.Status(), len(span.Events()))
+	}
+
+	sc := span.SpanContext()
+	if want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-01"; header.Get("Traceparent") != want {
+		t.Errorf("injected traceparent %q, want %q", header.Get("Traceparent"), want)
+	}
+}
+
+func TestNewTracerDefaultsToGlobalProvider(t *testing.T) {
+	if tr, ok := NewTracer(nil).(*tracer); !ok || tr.tracer == nil || tr.propagator == nil {
+		t.Errorf("NewTracer(nil) = %#v, want a tracer of the global provider", tr)
+	}
+}
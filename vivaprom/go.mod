@@ -0,0 +1,23 @@
+module github.com/techpals-eu/viva-wallet-go/vivaprom
+
+go 1.21
+
+require (
+	github.com/prometheus/client_golang v1.19.1
+	github.com/techpals-eu/viva-wallet-go v1.0.4
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/prometheus/client_model v0.5.0 // indirect
+	github.com/prometheus/common v0.48.0 // indirect
+	github.com/prometheus/procfs v0.12.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// The package is developed along with the root module.
+replace github.com/techpals-eu/viva-wallet-go => ../
@@ -0,0 +1,134 @@
+// Package vivaprom exports the measurements of the Viva clients as Prometheus metrics.
+//
+//	m, err := vivaprom.NewMetrics(prometheus.DefaultRegisterer)
+//	oauthClient.Metrics = m
+//	basicAuthClient.Metrics = m
+package vivaprom
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+const namespace = "viva"
+
+var (
+	_ vivawallet.Metrics          = (*Metrics)(nil)
+	_ vivawallet.RateLimitMetrics = (*Metrics)(nil)
+)
+
+// Metrics implements vivawallet.Metrics with Prometheus collectors.
+type Metrics struct {
+	requests       *prometheus.CounterVec
+	latency        *prometheus.HistogramVec
+	tokenRefreshes *prometheus.CounterVec
+	retries        *prometheus.CounterVec
+	webhooks       *prometheus.CounterVec
+	limitTokens    *prometheus.GaugeVec
+	limitRate      *prometheus.GaugeVec
+	limitWaiting   *prometheus.GaugeVec
+	limited        *prometheus.CounterVec
+}
+
+// NewMetrics creates the collectors and registers them with reg.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Requests to the Viva API by endpoint and status, status 0 meaning no response was received.",
+		}, []string{"endpoint", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of the requests to the Viva API by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		tokenRefreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "token_refreshes_total",
+			Help:      "Access token requests by result.",
+		}, []string{"result"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Requests to the Viva API sent again by endpoint.",
+		}, []string{"endpoint"}),
+		webhooks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhooks_total",
+			Help:      "Webhook events received by event type and result.",
+		}, []string{"event_type", "result"}),
+		limitTokens: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "rate_limit_tokens",
+			Help:      "Tokens available in the rate limiter bucket of a credential.",
+		}, []string{"credential"}),
+		limitRate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "rate_limit_rate",
+			Help:      "Current rate in requests per second of the rate limiter bucket of a credential.",
+		}, []string{"credential"}),
+		limitWaiting: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "rate_limit_waiting",
+			Help:      "Requests waiting for a token of the rate limiter bucket of a credential.",
+		}, []string{"credential"}),
+		limited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_total",
+			Help:      "Requests rejected by the rate limiter by credential.",
+		}, []string{"credential"}),
+	}
+
+	for _, c := range m.collectors() {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.requests, m.latency, m.tokenRefreshes, m.retries, m.webhooks,
+		m.limitTokens, m.limitRate, m.limitWaiting, m.limited,
+	}
+}
+
+func (m *Metrics) ObserveRequest(endpoint string, status int, latency time.Duration) {
+	m.requests.WithLabelValues(endpoint, strconv.Itoa(status)).Inc()
+	m.latency.WithLabelValues(endpoint).Observe(latency.Seconds())
+}
+
+func (m *Metrics) ObserveTokenRefresh(err error) {
+	m.tokenRefreshes.WithLabelValues(result(err)).Inc()
+}
+
+func (m *Metrics) ObserveRetry(endpoint string) {
+	m.retries.WithLabelValues(endpoint).Inc()
+}
+
+func (m *Metrics) ObserveWebhook(eventTypeID int, err error) {
+	m.webhooks.WithLabelValues(strconv.Itoa(eventTypeID), result(err)).Inc()
+}
+
+func (m *Metrics) ObserveRateLimit(state vivawallet.RateLimitState) {
+	m.limitTokens.WithLabelValues(state.Key).Set(state.Tokens)
+	m.limitRate.WithLabelValues(state.Key).Set(state.Rate)
+	m.limitWaiting.WithLabelValues(state.Key).Set(float64(state.Waiting))
+}
+
+func (m *Metrics) ObserveRateLimited(key string) {
+	m.limited.WithLabelValues(key).Inc()
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
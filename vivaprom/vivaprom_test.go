@@ -0,0 +1,76 @@
+package vivaprom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.ObserveRequest("GetWallets", 200, 20*time.Millisecond)
+	m.ObserveRequest("GetWallets", 200, 30*time.Millisecond)
+	m.ObserveRequest("GetWallets", 0, time.Second)
+	m.ObserveTokenRefresh(nil)
+	m.ObserveTokenRefresh(errors.New("unauthorized"))
+	m.ObserveRetry("GetWallets")
+	m.ObserveWebhook(1796, nil)
+	m.ObserveRateLimit(vivawallet.RateLimitState{Key: "merchant", Tokens: 2, Rate: 5, Waiting: 1})
+	m.ObserveRateLimited("merchant")
+
+	expected := `
+# HELP viva_requests_total Requests to the Viva API by endpoint and status, status 0 meaning no response was received.
+# TYPE viva_requests_total counter
+viva_requests_total{endpoint="GetWallets",status="0"} 1
+viva_requests_total{endpoint="GetWallets",status="200"} 2
+# HELP viva_token_refreshes_total Access token requests by result.
+# TYPE viva_token_refreshes_total counter
+viva_token_refreshes_total{result="failure"} 1
+viva_token_refreshes_total{result="success"} 1
+# HELP viva_retries_total Requests to the Viva API sent again by endpoint.
+# TYPE viva_retries_total counter
+viva_retries_total{endpoint="GetWallets"} 1
+# HELP viva_webhooks_total Webhook events received by event type and result.
+# TYPE viva_webhooks_total counter
+viva_webhooks_total{event_type="1796",result="success"} 1
+# HELP viva_rate_limit_rate Current rate in requests per second of the rate limiter bucket of a credential.
+# TYPE viva_rate_limit_rate gauge
+viva_rate_limit_rate{credential="merchant"} 5
+# HELP viva_rate_limit_waiting Requests waiting for a token of the rate limiter bucket of a credential.
+# TYPE viva_rate_limit_waiting gauge
+viva_rate_limit_waiting{credential="merchant"} 1
+# HELP viva_rate_limited_total Requests rejected by the rate limiter by credential.
+# TYPE viva_rate_limited_total counter
+viva_rate_limited_total{credential="merchant"} 1
+`
+	names := []string{
+		"viva_requests_total", "viva_token_refreshes_total", "viva_retries_total", "viva_webhooks_total",
+		"viva_rate_limit_rate", "viva_rate_limit_waiting", "viva_rate_limited_total",
+	}
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), names...); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(m.latency); n != 1 {
+		t.Errorf("%d latency series, want 1", n)
+	}
+}
+
+func TestNewMetricsRegistersOnce(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := NewMetrics(reg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewMetrics(reg); err == nil {
+		t.Error("registering the metrics twice succeeded, want an error")
+	}
+}
@@ -0,0 +1,297 @@
+// Package vivatest records the requests of the Viva clients into cassette files and
+// replays them, so that tests run the same flows offline.
+//
+//	rec := vivatest.Start(t, "testdata/checkout.json")
+//	oauthClient := vivawallet.NewOAuth(clientID, clientSecret, true, vivawallet.WithTransport(rec))
+//
+// Credentials, access tokens and card data are scrubbed before a cassette is saved.
+package vivatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+// RecordEnv is the environment variable making Start record the interactions again
+// when set to a non empty value.
+const RecordEnv = "VIVA_RECORD"
+
+// droppedBody replaces the bodies which cannot be scrubbed.
+const droppedBody = "[REDACTED]"
+
+// Mode tells whether a recorder records or replays the interactions.
+type Mode int
+
+const (
+	// ModeAuto replays the cassette if the file exists and records it otherwise.
+	ModeAuto Mode = iota
+	// ModeRecord sends the requests and records the interactions, replacing the
+	// cassette.
+	ModeRecord
+	// ModeReplay replays the cassette and fails the requests that were not recorded.
+	ModeReplay
+)
+
+// Cassette holds the interactions recorded in a file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper recording the interactions with Viva into a cassette
+// or replaying them. A recorded request is matched by method and url, in the order of
+// the recording, so that repeated calls replay their own responses.
+type Recorder struct {
+	// Path is the path of the cassette file.
+	Path string
+	// Transport sends the requests when recording. http.DefaultTransport is used when
+	// nil.
+	Transport http.RoundTripper
+	// Redaction lists the headers, json and form fields scrubbed from the cassette.
+	// DefaultRedactionRules are used when nil.
+	Redaction []vivawallet.RedactionRule
+	// ScrubBody, when set, scrubs the bodies which are neither json nor form encoded,
+	// such as the csv reports. They are replaced by [REDACTED] otherwise, since the
+	// redaction rules cannot be applied to them.
+	ScrubBody func(body []byte) []byte
+
+	recording bool
+	mu        sync.Mutex
+	cassette  Cassette
+	used      []bool
+}
+
+// DefaultRedactionRules returns the rules of the core package along with the expiration
+// dates, the api keys, the cardholder names and the card references, which a cassette
+// has no use for and which are committed along with the tests.
+func DefaultRedactionRules() []vivawallet.RedactionRule {
+	return append(vivawallet.DefaultRedactionRules(),
+		vivawallet.RedactionRule{Field: "cvv"},
+		vivawallet.RedactionRule{Field: "expirationDate"},
+		vivawallet.RedactionRule{Field: "apiKey"},
+		vivawallet.RedactionRule{Field: "fullName"},
+		vivawallet.RedactionRule{Field: "cardUniqueReference"},
+	)
+}
+
+// New creates a recorder for the cassette at path. The cassette is loaded unless the
+// recorder records.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{Path: path}
+
+	if mode == ModeAuto {
+		mode = ModeReplay
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			mode = ModeRecord
+		}
+	}
+	if mode == ModeRecord {
+		r.recording = true
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette %s", err)
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s", err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Start creates a recorder for the test and saves the cassette when the test ends. It
+// records when RecordEnv is set or the cassette does not exist, and replays otherwise.
+func Start(t testing.TB, path string) *Recorder {
+	t.Helper()
+
+	mode := ModeAuto
+	if os.Getenv(RecordEnv) != "" {
+		mode = ModeRecord
+	}
+	r, err := New(path, mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := r.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	return r
+}
+
+// Recording returns true if the recorder sends the requests and records them.
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+// RoundTrip sends the request when recording and replays the recorded response
+// otherwise.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if !r.recording {
+		return r.replay(req)
+	}
+
+	sent := req.Clone(req.Context())
+	if body != nil {
+		sent.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := r.transport().RoundTrip(sent)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response %s", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.record(Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: vivawallet.RedactHeader(req.Header, r.rules()),
+			Body:   r.scrub(body, req.Header.Get("Content-Type")),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     vivawallet.RedactHeader(resp.Header, r.rules()),
+			Body:       r.scrub(respBody, resp.Header.Get("Content-Type")),
+		},
+	})
+	return resp, nil
+}
+
+// Stop saves the cassette if the recorder records.
+func (r *Recorder) Stop() error {
+	if !r.recording {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette %s", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to save cassette %s", err)
+	}
+	if err := os.WriteFile(r.Path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to save cassette %s", err)
+	}
+	return nil
+}
+
+func (r *Recorder) record(i Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+}
+
+// replay returns the response of the first unused interaction matching the request.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	uri := req.URL.String()
+	for n, i := range r.cassette.Interactions {
+		if r.used[n] || i.Request.Method != req.Method || i.Request.URL != uri {
+			continue
+		}
+		r.used[n] = true
+
+		header := i.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Del("Content-Length")
+		return &http.Response{
+			Status:        strconv.Itoa(i.Response.StatusCode) + " " + http.StatusText(i.Response.StatusCode),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader([]byte(i.Response.Body))),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded interaction for %s %s in %s", req.Method, uri, r.Path)
+}
+
+func (r *Recorder) scrub(body []byte, contentType string) string {
+	data, ok := vivawallet.RedactBody(body, contentType, r.rules())
+	if ok {
+		return string(data)
+	}
+	if r.ScrubBody == nil {
+		return droppedBody
+	}
+	return string(r.ScrubBody(body))
+}
+
+func (r *Recorder) rules() []vivawallet.RedactionRule {
+	if r.Redaction == nil {
+		return DefaultRedactionRules()
+	}
+	return r.Redaction
+}
+
+func (r *Recorder) transport() http.RoundTripper {
+	if r.Transport == nil {
+		return http.DefaultTransport
+	}
+	return r.Transport
+}
+
+// readBody reads and closes the body of the request, as a transport must.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer req.Body.Close()
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request %s", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,118 @@
+package vivatest
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	vivawallet "github.com/techpals-eu/viva-wallet-go"
+)
+
+func TestScrub(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		scrub       func([]byte) []byte
+		want        string
+	}{
+		{"json", `{"cardNumber":"4111111111111111","amount":100}`, "application/json", nil, `{"amount":100,"cardNumber":"[REDACTED]"}`},
+		{"form", "client_secret=secret&grant_type=client_credentials", "application/x-www-form-urlencoded", nil, "client_secret=%5BREDACTED%5D&grant_type=client_credentials"},
+		{"empty", "", "text/csv", nil, ""},
+		{"unknown dropped", "email,amount\nuser@example.com,100\n", "text/csv", nil, droppedBody},
+		{"unknown scrubbed", "email,amount\nuser@example.com,100\n", "text/csv", func(b []byte) []byte {
+			return []byte(strings.Split(string(b), "\n")[0])
+		}, "email,amount"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Recorder{ScrubBody: tt.scrub}
+			if got := r.scrub([]byte(tt.body), tt.contentType); got != tt.want {
+				t.Errorf("scrub() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDefaultRedactionRulesFixture checks that the committed cassette of the example
+// holds no value of a field the default rules redact.
+func TestDefaultRedactionRulesFixture(t *testing.T) {
+	data, err := os.ReadFile("../example/testdata/example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := DefaultRedactionRules()
+	for n, i := range c.Interactions {
+		for _, h := range []http.Header{i.Request.Header, i.Response.Header} {
+			for name, values := range h {
+				if isRedacted(rules, name) {
+					for _, v := range values {
+						if v != "[REDACTED]" {
+							t.Errorf("interaction %d header %s = %q, want it redacted", n, name, v)
+						}
+					}
+				}
+			}
+		}
+		for _, body := range []string{i.Request.Body, i.Response.Body} {
+			var v interface{}
+			if json.Unmarshal([]byte(body), &v) != nil {
+				continue
+			}
+			for _, leak := range leakedFields(v, rules) {
+				t.Errorf("interaction %d %s %s field %s is not redacted", n, i.Request.Method, i.Request.URL, leak)
+			}
+		}
+	}
+}
+
+func isRedacted(rules []vivawallet.RedactionRule, name string) bool {
+	for _, r := range rules {
+		if strings.EqualFold(r.Field, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// leakedFields returns the redacted fields of a json value holding anything but
+// [REDACTED] or nothing.
+func leakedFields(v interface{}, rules []vivawallet.RedactionRule) []string {
+	var leaks []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if !isRedacted(rules, k) {
+				leaks = append(leaks, leakedFields(field, rules)...)
+				continue
+			}
+			switch field := field.(type) {
+			case nil:
+			case string:
+				if field != "[REDACTED]" {
+					leaks = append(leaks, k)
+				}
+			case []interface{}:
+				for _, e := range field {
+					if e != "[REDACTED]" {
+						leaks = append(leaks, k)
+					}
+				}
+			default:
+				leaks = append(leaks, k)
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			leaks = append(leaks, leakedFields(e, rules)...)
+		}
+	}
+	return leaks
+}
@@ -2,8 +2,11 @@ package vivawallet
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	"net/http"
-	"sync"
 	"time"
 )
 
@@ -13,38 +16,63 @@ type Config struct {
 	ClientSecret string
 	MerchantID   string
 	APIKey       string
-}
-
-type token struct {
-	value   string
-	expires time.Time
+	// Scopes requested when authenticating. Viva grants the default scopes of the
+	// client credentials when empty.
+	Scopes []string
+	// BaseURL, when set, replaces the Viva hosts, e.g. to use a mock server.
+	BaseURL string
 }
 
 type OAuthClient struct {
-	Config     Config
-	Client     *http.Client
-	lock       *sync.RWMutex
-	tokenValue *token
+	Config Config
+	Client *http.Client
+	// UserAgent, when set, is sent with every request of the client.
+	UserAgent string
+	// Tokens stores the access token of the client. It defaults to an in-memory store.
+	Tokens TokenStore
+	// Logger, when set, logs every request of the client. The headers and bodies are
+	// logged at debug level, after being redacted according to Redaction.
+	Logger *slog.Logger
+	// Redaction lists the values hidden from the logs. DefaultRedactionRules are used
+	// when nil.
+	Redaction []RedactionRule
+	// Tracer, when set, traces every request of the client.
+	Tracer Tracer
+	// Metrics, when set, receives the measurements of the client.
+	Metrics Metrics
+	// Limiter, when set, limits the rate of the requests of the client. It may be
+	// shared between clients.
+	Limiter *RateLimiter
+	// Idempotency, when set, journals the create calls made with an idempotency key.
+	Idempotency IdempotencyStore
+	flight      *authFlight
 }
 
 type BasicAuthClient struct {
 	Config Config
 	Client *http.Client
+	// UserAgent, when set, is sent with every request of the client.
+	UserAgent string
+	// Logger, when set, logs every request of the client. The headers and bodies are
+	// logged at debug level, after being redacted according to Redaction.
+	Logger *slog.Logger
+	// Redaction lists the values hidden from the logs. DefaultRedactionRules are used
+	// when nil.
+	Redaction []RedactionRule
+	// Tracer, when set, traces every request of the client.
+	Tracer Tracer
+	// Metrics, when set, receives the measurements of the client.
+	Metrics Metrics
+	// Limiter, when set, limits the rate of the requests of the client. It may be
+	// shared between clients.
+	Limiter *RateLimiter
+	// Idempotency, when set, journals the create calls made with an idempotency key.
+	Idempotency IdempotencyStore
 }
 
-// defaultHTTPTimeout is the default timeout on the http.Client used by the library.
+// defaultTimeout is the default timeout on the http.Client used by the library.
 const defaultTimeout = 60 * time.Second
 
-var (
-	httpClient *http.Client
-)
-
-func init() {
-	httpClient = &http.Client{
-		Timeout: defaultTimeout,
-	}
-}
-
 type Client interface {
 	Get(uri string, v interface{}) error
 	Post(uri string, reader *bytes.Reader, v interface{}) error
@@ -53,6 +81,9 @@ type Client interface {
 
 // ApiUri returns the uri of the production or the demo api.
 func ApiUri(c Config) string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
 	if isDemo(c) {
 		return "https://demo-api.vivapayments.com"
 	}
@@ -60,6 +91,9 @@ func ApiUri(c Config) string {
 }
 
 func AppUri(c Config) string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
 	if isDemo(c) {
 		return "https://demo.vivapayments.com"
 	}
@@ -70,12 +104,28 @@ func isDemo(c Config) bool {
 	return c.Demo
 }
 
-func newRequest(method string, uri string, reader *bytes.Reader) *http.Request {
+func newRequest(ctx context.Context, method string, uri string, reader *bytes.Reader) *http.Request {
 	var req *http.Request
 	if reader != nil {
-		req, _ = http.NewRequest(method, uri, reader)
+		req, _ = http.NewRequestWithContext(ctx, method, uri, reader)
 	} else {
-		req, _ = http.NewRequest(method, uri, nil)
+		req, _ = http.NewRequestWithContext(ctx, method, uri, nil)
+	}
+
+	if key, ok := ctx.Value(idempotencyHeaderKey{}).(string); ok {
+		req.Header.Set(IdempotencyHeader, key)
 	}
 	return req
 }
+
+// jsonBody returns a reader over the json encoding of v, or nil if v is nil.
+func jsonBody(v interface{}, name string) (*bytes.Reader, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s %s", name, err)
+	}
+	return bytes.NewReader(data), nil
+}
@@ -1,8 +1,7 @@
 package vivawallet
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 )
 
@@ -21,24 +20,43 @@ type BalanceTransferResponse struct {
 	CreditTransactionID string `json:"CreditTransactionId"`
 }
 
-// BalanceTransfer transfers money from one wallet to another.
+// WalletsService handles the wallet endpoints.
+type WalletsService service
+
+// Transfer transfers money from one wallet to another.
 // Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Balance-Transfer/paths/~1api~1wallets~1{walletId}~1balancetransfer~1{targetWalletId}/post
-func (c BasicAuthClient) BalanceTranfer(walletID string, targetWalletID string, payload BalanceTransfer) (*BalanceTransferResponse, error) {
+func (s *WalletsService) Transfer(ctx context.Context, walletID string, targetWalletID string, payload BalanceTransfer) (*BalanceTransferResponse, error) {
+	ctx = withOperation(ctx, "BalanceTransfer")
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return nil, err
+	}
 
 	uri := getBalanceTransferUri(c.Config, walletID, targetWalletID)
-	data, err := json.Marshal(payload)
+	body, err := jsonBody(payload, "BalanceTransfer")
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse BalanceTransfer %s", err)
+		return nil, err
 	}
 
 	b := &BalanceTransferResponse{}
-	reqErr := c.Post(uri, bytes.NewReader(data), &b)
+	reqErr := c.do(ctx, "POST", uri, body, b)
 	if reqErr != nil {
 		return nil, reqErr
 	}
 	return b, nil
 }
 
+// BalanceTranfer transfers money from one wallet to another.
+//
+// Deprecated: use API.Wallets.Transfer instead.
+func (c BasicAuthClient) BalanceTranfer(walletID string, targetWalletID string, payload BalanceTransfer) (*BalanceTransferResponse, error) {
+	return NewAPI(nil, &c).Wallets.Transfer(context.Background(), walletID, targetWalletID, payload)
+}
+
 func getWalletsUri(c Config) string {
 	return fmt.Sprintf("%s/api/wallets", AppUri(c))
 }
@@ -54,15 +72,28 @@ type Wallet struct {
 	CurrencyCode string  `json:"CurrencyCode"`
 }
 
-// GetWallets fetches a list of wallets associated to your account.
+// List fetches the wallets associated to your account.
 // Ref: https://developer.vivawallet.com/apis-for-payments/payment-api/#tag/Retrieve-Wallet/paths/~1api~1wallets/get
-func (c BasicAuthClient) GetWallets() ([]Wallet, error) {
+func (s *WalletsService) List(ctx context.Context) ([]Wallet, error) {
+	ctx = withOperation(ctx, "GetWallets")
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return nil, err
+	}
+
 	uri := getWalletsUri(c.Config)
 
 	var r []Wallet
-	err := c.Get(uri, &r)
-	if err != nil {
-			return nil, err
+	reqErr := c.do(ctx, "GET", uri, nil, &r)
+	if reqErr != nil {
+		return nil, reqErr
 	}
 	return r, nil
 }
+
+// GetWallets fetches a list of wallets associated to your account.
+//
+// Deprecated: use API.Wallets.List instead.
+func (c BasicAuthClient) GetWallets() ([]Wallet, error) {
+	return NewAPI(nil, &c).Wallets.List(context.Background())
+}
@@ -0,0 +1,144 @@
+package vivawallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WebhooksService handles the webhook verification key and the parsing of the events
+// Viva sends to your webhook url.
+type WebhooksService service
+
+// Webhook event types sent by Viva.
+const (
+	EventTransactionPaymentCreated  = 1796
+	EventTransactionReversalCreated = 1797
+	EventTransactionFailed          = 1798
+	EventAccountTransactionCreated  = 768
+	EventBankTransferCreated        = 769
+	EventBankTransferExecuted       = 770
+)
+
+type WebhookKey struct {
+	Key string `json:"Key"`
+}
+
+// Get fetches the key Viva expects your webhook url to respond with when it is verified.
+// Ref: https://developer.vivawallet.com/webhooks-for-payments/#generate-a-webhook-verification-key
+func (s *WebhooksService) Get(ctx context.Context) (*WebhookKey, error) {
+	ctx = withOperation(ctx, "GetWebhookKey")
+	c, err := service(*s).basicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	uri := getWebhookKeyUri(c.Config)
+
+	k := &WebhookKey{}
+	reqErr := c.do(ctx, "GET", uri, nil, k)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	return k, nil
+}
+
+func getWebhookKeyUri(c Config) string {
+	return fmt.Sprintf("%s/api/messages/config/token", AppUri(c))
+}
+
+type WebhookEvent struct {
+	URL           string          `json:"Url"`
+	EventData     json.RawMessage `json:"EventData"`
+	Created       time.Time       `json:"Created"`
+	CorrelationID string          `json:"CorrelationId"`
+	EventTypeID   int             `json:"EventTypeId"`
+	Delay         *int            `json:"Delay"`
+	MessageID     string          `json:"MessageId"`
+	RecipientID   string          `json:"RecipientId"`
+	MessageTypeID int             `json:"MessageTypeId"`
+}
+
+// TransactionEventData is the payload of the transaction events.
+type TransactionEventData struct {
+	TransactionID     string    `json:"TransactionId"`
+	OrderCode         int64     `json:"OrderCode"`
+	Amount            float64   `json:"Amount"`
+	StatusID          string    `json:"StatusId"`
+	CurrencyCode      string    `json:"CurrencyCode"`
+	InsDate           time.Time `json:"InsDate"`
+	SourceCode        string    `json:"SourceCode"`
+	MerchantTrns      string    `json:"MerchantTrns"`
+	CustomerTrns      string    `json:"CustomerTrns"`
+	Email             string    `json:"Email"`
+	FullName          string    `json:"FullName"`
+	CardNumber        string    `json:"CardNumber"`
+	TransactionTypeID int       `json:"TransactionTypeId"`
+}
+
+// Transaction decodes the event data of a transaction event.
+func (e WebhookEvent) Transaction() (*TransactionEventData, error) {
+	t := &TransactionEventData{}
+	if err := json.Unmarshal(e.EventData, t); err != nil {
+		return nil, fmt.Errorf("failed to parse event data %s", err)
+	}
+	return t, nil
+}
+
+// Handler returns an http.Handler to serve as your webhook url. It responds to the
+// verification requests of Viva with the key fetched by Get, and calls fn for every
+// event received. When fn returns an error the handler responds with a non successful
+// status so that Viva sends the event again.
+func (s *WebhooksService) Handler(fn func(ctx context.Context, e WebhookEvent) error) http.Handler {
+	var (
+		lock sync.Mutex
+		key  *WebhookKey
+	)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			lock.Lock()
+			if key == nil {
+				k, err := s.Get(r.Context())
+				if err != nil {
+					lock.Unlock()
+					http.Error(w, "failed to fetch verification key", http.StatusBadGateway)
+					return
+				}
+				key = k
+			}
+			k := *key
+			lock.Unlock()
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(k)
+		case "POST":
+			m := service(*s).metrics()
+
+			e := WebhookEvent{}
+			if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+				if m != nil {
+					m.ObserveWebhook(0, err)
+				}
+				http.Error(w, "invalid event", http.StatusBadRequest)
+				return
+			}
+
+			err := fn(r.Context(), e)
+			if m != nil {
+				m.ObserveWebhook(e.EventTypeID, err)
+			}
+			if err != nil {
+				http.Error(w, "failed to handle event", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}